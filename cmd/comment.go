@@ -48,6 +48,7 @@ var conCmd = &cobra.Command{
 		_ = viper.BindPFlag("input", cmd.Flags().Lookup("input"))
 		_ = viper.BindPFlag("output", cmd.Flags().Lookup("output"))
 		_ = viper.BindPFlag("type", cmd.Flags().Lookup("type"))
+		_ = viper.BindPFlag("comment.template", cmd.Flags().Lookup("template"))
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fset := token.NewFileSet()
@@ -76,7 +77,7 @@ var conCmd = &cobra.Command{
 		if f.Name != nil {
 			pkg = f.Name.Name
 		}
-		code, err := comment.GetConCode(viper.GetString("type"), pkg, comments)
+		code, err := comment.GetConCode(viper.GetString("type"), pkg, comments, templateFile("comment.template", "comment"))
 		cobra.CheckErr(err)
 		if commentOut == "" {
 			commentOut = strings.TrimSuffix(viper.GetString("input"), ".go") + "_msg.go"
@@ -93,4 +94,5 @@ func init() {
 	conCmd.Flags().StringVarP(&commentInput, "input", "i", os.Getenv("GOFILE"), `需要提取的文件`)
 	conCmd.Flags().StringVarP(&commentOut, "output", "o", "", `输出文件`)
 	conCmd.Flags().StringVarP(&constType, "type", "t", "int", "常量类型")
+	conCmd.Flags().String("template", "", "自定义模版文件，默认使用配置 template.dir 下的 comment.tpl")
 }
@@ -0,0 +1,96 @@
+// Package cmd
+// @Title 配置文件查找
+// @Description 依次读取家目录及项目目录下的 .tool-cli.yaml 并合并，项目配置优先，配置文件均不存在时使用默认值
+// @Author shigx 2024-09-23 10:18:45
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+)
+
+// configNames 配置文件名，同一目录下取第一个存在的
+var configNames = []string{".tool-cli.yaml", ".tool-cli.yml"}
+
+// readConfig
+//
+//	@Description: 读取配置文件，指定 --config 时只读取该文件，不存在或格式错误时返回错误；
+//	否则先读取家目录下的配置，再读取从当前目录向上查找到的第一个项目配置，项目配置覆盖家目录配置，均不存在时不报错
+//	@Auth shigx 2024-09-23 10:18:45
+//	@return error
+func readConfig() error {
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+		if err := viper.ReadInConfig(); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Using config file:", cfgFile)
+		return nil
+	}
+
+	files := make([]string, 0, 2)
+	if home, err := os.UserHomeDir(); err == nil {
+		if file := findConfig(home); file != "" {
+			files = append(files, file)
+		}
+	}
+	if file := findProjectConfig(); file != "" && (len(files) == 0 || !sameFile(file, files[0])) {
+		files = append(files, file)
+	}
+
+	for _, file := range files {
+		viper.SetConfigFile(file)
+		if err := viper.MergeInConfig(); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Using config file:", file)
+	}
+
+	return nil
+}
+
+// findProjectConfig 从当前目录向上查找项目配置文件，未找到时返回空
+func findProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if file := findConfig(dir); file != "" {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findConfig 返回目录下的配置文件，不存在时返回空
+func findConfig(dir string) string {
+	for _, name := range configNames {
+		file := filepath.Join(dir, name)
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			return file
+		}
+	}
+
+	return ""
+}
+
+// sameFile 判断两个路径是否为同一文件
+func sameFile(a, b string) bool {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,63 @@
+// Package cmd
+// @Title 将mysql数据表生成ER图
+// @Description 将表结构、键及外键生成 Mermaid、PlantUML ER图
+// @Author shigx 2024-07-08 14:36:20
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"tool-cli/internal/erd"
+)
+
+var erdCmd = &cobra.Command{
+	Use:   "erd",
+	Short: "将mysql表生成ER图",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindSourceFlags(cmd)
+		_ = viper.BindPFlag("erd.format", cmd.Flags().Lookup("format"))
+		_ = viper.BindPFlag("erd.output", cmd.Flags().Lookup("output"))
+		_ = viper.BindPFlag("erd.markdown", cmd.Flags().Lookup("markdown"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		source, err := openSource(cmd)
+		cobra.CheckErr(err)
+		defer func() {
+			// 关闭数据库连接
+			cobra.CheckErr(source.Close())
+		}()
+
+		tables, err := source.loadTables()
+		cobra.CheckErr(err)
+
+		format := viper.GetString("erd.format")
+		var content string
+		if viper.GetBool("erd.markdown") {
+			content, err = erd.Markdown(format, tables)
+		} else {
+			content, err = erd.Generate(format, tables)
+		}
+		cobra.CheckErr(err)
+
+		output := viper.GetString("erd.output")
+		if output == "" {
+			fmt.Print(content)
+			return
+		}
+		if dir := filepath.Dir(output); dir != "" {
+			cobra.CheckErr(os.MkdirAll(dir, 0755))
+		}
+		cobra.CheckErr(os.WriteFile(output, []byte(content), 0644))
+		fmt.Printf("生成ER图：共 %d 个表，输出文件：%s\n", len(tables), output)
+	},
+}
+
+func init() {
+	addSourceFlags(erdCmd)
+	erdCmd.Flags().String("format", erd.FormatMermaid, "ER图格式，mermaid、plantuml")
+	erdCmd.Flags().StringP("output", "o", "", "输出文件，默认输出到标准输出")
+	erdCmd.Flags().Bool("markdown", false, "输出为可嵌入markdown文档的代码块")
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveConnPrecedence(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	passFile := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(passFile, []byte("file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		args   []string
+		env    string
+		want   string
+		prompt bool
+	}{
+		{"config pass", "pass: config", nil, "", "config", false},
+		{"env over config", "pass: config", nil, "env", "env", false},
+		{"flag over env", "pass: config", []string{"--pass=flag"}, "env", "flag", false},
+		{"flag pass-file over config pass", "pass: config", []string{"--pass-file=" + passFile}, "env", "file", false},
+		{"config pass-file", "pass-file: " + passFile, nil, "", "file", false},
+		{"dsn password", "pass: config\n  dsn: app:dsn@tcp(db:3306)/shop", nil, "env", "dsn", false},
+		{"no password", "user: app", nil, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			viper.SetConfigType("yaml")
+			if err := viper.ReadConfig(strings.NewReader("mysql:\n  " + tt.config + "\n")); err != nil {
+				t.Fatal(err)
+			}
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("pass", "", "")
+			flags.String("pass-file", "", "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			_ = viper.BindPFlag("mysql.pass", flags.Lookup("pass"))
+			_ = viper.BindPFlag("mysql.pass-file", flags.Lookup("pass-file"))
+			for _, env := range []string{passwordEnv, "MYSQL_PWD"} {
+				t.Setenv(env, "")
+				_ = os.Unsetenv(env)
+			}
+			if tt.env != "" {
+				t.Setenv(passwordEnv, tt.env)
+			}
+
+			conn, err := resolveConn(flags, "")
+			if err != nil {
+				t.Fatalf("resolveConn() error = %v", err)
+			}
+			if conn.password != tt.want {
+				t.Errorf("resolveConn() password = %q, want %q", conn.password, tt.want)
+			}
+			if conn.prompt != tt.prompt {
+				t.Errorf("resolveConn() prompt = %v, want %v", conn.prompt, tt.prompt)
+			}
+		})
+	}
+}
+
+func TestDsnConn(t *testing.T) {
+	passFile := filepath.Join(t.TempDir(), "pass")
+	if err := os.WriteFile(passFile, []byte("file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		location string
+		driver   string
+		args     []string
+		env      map[string]string
+		want     string
+		prompt   bool
+	}{
+		{"dsn password", "app:dsn@tcp(db:3306)/shop", "mysql", []string{"--pass-file=" + passFile}, map[string]string{passwordEnv: "env"}, "dsn", false},
+		{"pass-file over env", "app@tcp(db:3306)/shop", "mysql", []string{"--pass-file=" + passFile}, map[string]string{passwordEnv: "env"}, "file", false},
+		{"tool env", "app@tcp(db:3306)/shop", "mysql", nil, map[string]string{passwordEnv: "env", "MYSQL_PWD": "mysql"}, "env", false},
+		{"mysql env", "app@tcp(db:3306)/shop", "mysql", nil, map[string]string{"MYSQL_PWD": "mysql"}, "mysql", false},
+		{"postgres env", "postgres://app@db/shop", "postgres", nil, map[string]string{"MYSQL_PWD": "mysql", "PGPASSWORD": "pg"}, "pg", false},
+		{"no password", "app@tcp(db:3306)/shop", "mysql", nil, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("pass-file", "", "")
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			for _, env := range []string{passwordEnv, "MYSQL_PWD", "PGPASSWORD"} {
+				t.Setenv(env, "")
+				_ = os.Unsetenv(env)
+			}
+			for env, value := range tt.env {
+				t.Setenv(env, value)
+			}
+
+			conn, err := dsnConn(flags, tt.location, tt.driver)
+			if err != nil {
+				t.Fatalf("dsnConn() error = %v", err)
+			}
+			if conn.password != tt.want || conn.prompt != tt.prompt {
+				t.Errorf("dsnConn() = %q, %v, want %q, %v", conn.password, conn.prompt, tt.want, tt.prompt)
+			}
+		})
+	}
+}
+
+func TestDsnPassword(t *testing.T) {
+	tests := []struct {
+		location string
+		password string
+		name     string
+	}{
+		{"root:p@ss@tcp(127.0.0.1:3306)/shop", "p@ss", "root:***@tcp(127.0.0.1:3306)/shop"},
+		{"postgres://app:secret@db:5432/shop?search_path=app", "secret", "postgres://app:***@db:5432/shop?search_path=app"},
+		{"root@tcp(127.0.0.1:3306)/shop", "", "root@tcp(127.0.0.1:3306)/shop"},
+		{"./ddl", "", "./ddl"},
+	}
+	for _, tt := range tests {
+		password, _ := dsnPassword(tt.location)
+		if password != tt.password {
+			t.Errorf("dsnPassword(%q) = %q, want %q", tt.location, password, tt.password)
+		}
+		if name := schemaName(tt.location); name != tt.name {
+			t.Errorf("schemaName(%q) = %q, want %q", tt.location, name, tt.name)
+		}
+	}
+
+	err := redactError(errors.New("access denied for app:secret@db"), "secret")
+	if err.Error() != "access denied for app:***@db" {
+		t.Errorf("redactError() = %q", err)
+	}
+}
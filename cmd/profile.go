@@ -0,0 +1,53 @@
+// Package cmd
+// @Title 连接配置profile
+// @Description 配置文件 profiles 下按名称定义多套配置，使用时合并到顶层配置，命令行参数优先
+// @Author shigx 2024-09-09 11:02:36
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"os"
+	"sort"
+	"strings"
+)
+
+var profile string // 使用的profile名称
+
+// applyProfile
+//
+//	@Description: 使用 --profile 或配置 default_profile 指定的profile，profile中的配置覆盖顶层同名配置，未指定时不处理
+//	@Auth shigx 2024-09-09 11:02:36
+//	@return error profile不存在时返回错误
+func applyProfile() error {
+	name := profile
+	if name == "" {
+		name = viper.GetString("default_profile")
+	}
+	if name == "" {
+		return nil
+	}
+
+	settings, ok := viper.Get("profiles." + name).(map[string]interface{})
+	if !ok {
+		return errors.Errorf("profile %s 不存在，可选：%s", name, strings.Join(profileNames(), "、"))
+	}
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Using profile:", name)
+
+	return nil
+}
+
+// profileNames 返回配置中的全部profile名称，按名称排序
+func profileNames() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
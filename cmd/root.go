@@ -16,10 +16,10 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
+	"path/filepath"
 )
 
 var cfgFile string
@@ -50,7 +50,8 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.tool-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.tool-cli.yaml merged with the nearest .tool-cli.yaml from the current directory up)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "使用配置文件 profiles 下的指定配置，默认为配置 default_profile")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -60,29 +61,37 @@ func init() {
 	rootCmd.AddCommand(commentCmd)
 	rootCmd.AddCommand(sql2mdCmd)
 	rootCmd.AddCommand(sql2structCmd)
+	rootCmd.AddCommand(struct2sqlCmd)
+	rootCmd.AddCommand(erdCmd)
+	rootCmd.AddCommand(schemaCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".tool-cli" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".tool-cli")
-	}
-
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err != nil {
-		cobra.CheckErr(err)
+	// Config files are optional unless given by --config.
+	cobra.CheckErr(readConfig())
+	cobra.CheckErr(applyProfile())
+}
+
+// templateFile
+//
+//	@Description: 返回自定义模版文件，优先使用命令参数，其次为配置 template.dir 目录下的 <name>.tpl，都不存在时返回空使用默认模版
+//	@Auth shigx 2024-06-24 10:30:52
+//	@param key 模版参数配置项
+//	@param name 模版名
+//	@return string
+func templateFile(key string, name string) string {
+	if file := viper.GetString(key); file != "" {
+		return file
 	}
-	fmt.Println("Using config file:", viper.ConfigFileUsed())
+	if dir := viper.GetString("template.dir"); dir != "" {
+		file := filepath.Join(dir, name+".tpl")
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+
+	return ""
 }
@@ -0,0 +1,360 @@
+// Package cmd
+// @Description: 表结构加载，支持从mysql、postgres数据库、建表语句或快照读取
+// @Auth shigx 2024-06-03 10:12:30
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"path"
+	"strings"
+	"tool-cli/internal/datasource"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// schemaCmd 表结构相关命令
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "表结构对比等操作",
+	Run: func(cmd *cobra.Command, args []string) {
+		cobra.CheckErr(cmd.Help())
+	},
+}
+
+// schemaSource 表结构来源，数据库、建表语句或快照
+type schemaSource struct {
+	reader schema.Reader // 表结构读取接口
+	file   bool          // 是否建表语句、快照模式
+	names  []string      // 全部表名
+}
+
+// addSourceFlags
+//
+//	@Description: 注册表结构来源及表选择参数
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param cmd
+func addSourceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("driver", schema.DriverMySQL, "数据库驱动："+strings.Join(schema.Drivers, "、"))
+	cmd.Flags().String("addr", "", "请输入db地址，默认mysql为127.0.0.1:3306，postgres为127.0.0.1:5432")
+	cmd.Flags().String("user", "", "请输入db用户名，默认mysql为root，postgres为postgres")
+	cmd.Flags().String("pass", "", "请输入db密码，会出现在命令历史中，建议使用 --pass-file、环境变量 TOOL_CLI_DB_PASS 或终端输入")
+	cmd.Flags().String("pass-file", "", "从文件读取db密码，忽略行尾换行")
+	cmd.Flags().String("login-path", "", "mysql选项文件 ~/.my.cnf、~/.mylogin.cnf 中的登录路径，默认读取 [client]")
+	cmd.Flags().String("db", "", "请输入db名称")
+	cmd.Flags().String("schema", "", "postgres的schema，默认public")
+	cmd.Flags().String("dsn", "", "完整dsn，指定后忽略 addr、user、pass、db，sqlite为数据库文件路径")
+	cmd.Flags().String("socket", "", "mysql unix socket路径，指定后忽略 addr")
+	cmd.Flags().String("tls", "", "mysql TLS模式：true、false、skip-verify、preferred，指定证书文件时默认为true")
+	cmd.Flags().String("tls-ca", "", "mysql TLS CA证书文件")
+	cmd.Flags().String("tls-cert", "", "mysql TLS 客户端证书文件")
+	cmd.Flags().String("tls-key", "", "mysql TLS 客户端私钥文件")
+	cmd.Flags().Duration("timeout", 0, "mysql连接超时，例：5s")
+	cmd.Flags().Duration("read-timeout", 0, "mysql读超时，例：30s")
+	cmd.Flags().StringToString("param", nil, "mysql其他dsn参数，可多次指定，例：collation=utf8mb4_bin,allowCleartextPasswords=true")
+	cmd.Flags().StringSlice("table", nil, "请输入表名，支持多个及通配符，例：user,order_*")
+	cmd.Flags().StringSlice("exclude", nil, "排除的表名，支持多个及通配符")
+	cmd.Flags().Bool("all", false, "处理库中全部表")
+	cmd.Flags().String("ddl", "", "建表语句文件或目录，- 表示标准输入，指定后不再连接数据库")
+	cmd.Flags().String("from-snapshot", "", "schema dump 生成的表结构快照文件，指定后不再连接数据库")
+}
+
+// bindSourceFlags
+//
+//	@Description: 绑定表结构来源参数到配置
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param cmd
+func bindSourceFlags(cmd *cobra.Command) {
+	for _, name := range []string{"driver", "addr", "user", "pass", "pass-file", "login-path", "db", "schema", "dsn", "socket", "tls", "tls-ca", "tls-cert", "tls-key", "timeout", "read-timeout", "param", "table", "exclude", "all", "ddl", "from-snapshot"} {
+		_ = viper.BindPFlag("mysql."+name, cmd.Flags().Lookup(name))
+	}
+}
+
+// openSource
+//
+//	@Description: 按参数打开表结构来源，指定快照时读取快照，指定ddl时解析建表语句，否则连接数据库
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param cmd
+//	@return *schemaSource
+//	@return error
+func openSource(cmd *cobra.Command) (*schemaSource, error) {
+	if location := viper.GetString("mysql.from-snapshot"); location != "" {
+		snapshot, err := schema.ReadSnapshot(location)
+		if err != nil {
+			return nil, err
+		}
+		// 优先使用快照中的数据库名
+		dbName := snapshot.Database
+		if dbName == "" {
+			dbName = viper.GetString("mysql.db")
+		}
+		return newFileSource(schema.NewStatic(snapshot.Driver, dbName, snapshot.Tables))
+	}
+	if ddl := viper.GetString("mysql.ddl"); ddl != "" {
+		tables, err := mysql.ReadDdl(ddl)
+		if err != nil {
+			return nil, err
+		}
+		return newFileSource(schema.NewStatic(schema.DriverMySQL, viper.GetString("mysql.db"), tables))
+	}
+
+	driver := viper.GetString("mysql.driver")
+	conn, err := resolveConn(cmd.Flags(), driver)
+	if err != nil {
+		return nil, err
+	}
+	config := &datasource.Config{
+		Driver:   driver,
+		Addr:     conn.addr,
+		User:     conn.user,
+		Password: conn.password,
+		DbName:   viper.GetString("mysql.db"),
+		Schema:   viper.GetString("mysql.schema"),
+		Dsn:      viper.GetString("mysql.dsn"),
+		// mysql连接选项
+		Socket:      conn.socket,
+		TLS:         viper.GetString("mysql.tls"),
+		TLSCa:       viper.GetString("mysql.tls-ca"),
+		TLSCert:     viper.GetString("mysql.tls-cert"),
+		TLSKey:      viper.GetString("mysql.tls-key"),
+		Timeout:     viper.GetDuration("mysql.timeout"),
+		ReadTimeout: viper.GetDuration("mysql.read-timeout"),
+		Params:      viper.GetStringMapString("mysql.param"),
+	}
+	reader, err := datasource.Open(config)
+	if retry, promptErr := conn.promptOnDenied(err); promptErr != nil {
+		return nil, promptErr
+	} else if retry {
+		config.Password = conn.password
+		reader, err = datasource.Open(config)
+	}
+	if err != nil {
+		return nil, redactError(err, conn.password)
+	}
+	names, err := reader.TableNames()
+	if err != nil {
+		_ = reader.Close()
+		return nil, redactError(err, conn.password)
+	}
+
+	return &schemaSource{reader: reader, names: names}, nil
+}
+
+// loadLocation
+//
+//	@Description: 按位置加载整库表结构，dsn未包含密码时使用 --pass-file 或环境变量中的密码，错误信息中的密码替换为 ***
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param flags 命令行参数
+//	@param location dsn、快照或建表语句文件、目录
+//	@return *schema.Schema
+//	@return error
+func loadLocation(flags *pflag.FlagSet, location string) (*schema.Schema, error) {
+	driver := datasource.DsnDriver(location)
+	if driver == "" || driver == schema.DriverSqlite {
+		return datasource.LoadLocation(location)
+	}
+	conn, err := dsnConn(flags, location, driver)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := loadDsn(location, conn.password)
+	if retry, promptErr := conn.promptOnDenied(err); promptErr != nil {
+		return nil, promptErr
+	} else if retry {
+		ret, err = loadDsn(location, conn.password)
+	}
+
+	return ret, redactError(err, conn.password)
+}
+
+// loadDsn 使用dsn加载整库表结构，密码不为空时替换dsn中的密码
+func loadDsn(dsn string, password string) (*schema.Schema, error) {
+	if password != "" {
+		var err error
+		if dsn, err = datasource.SetDsnPassword(dsn, password); err != nil {
+			return nil, err
+		}
+	}
+
+	return datasource.LoadLocation(dsn)
+}
+
+// newFileSource 创建建表语句、快照表结构来源
+func newFileSource(reader schema.Reader) (*schemaSource, error) {
+	names, err := reader.TableNames()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaSource{reader: reader, file: true, names: names}, nil
+}
+
+// Table
+//
+//	@Description: 返回表结构信息
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param name
+//	@return *schema.Table
+//	@return error
+func (s *schemaSource) Table(name string) (*schema.Table, error) {
+	return s.reader.Table(name)
+}
+
+// Driver 返回表结构来源的数据库驱动
+func (s *schemaSource) Driver() string {
+	return s.reader.Driver()
+}
+
+// Database 返回数据库名
+func (s *schemaSource) Database() string {
+	return s.reader.Database()
+}
+
+// Close
+//
+//	@Description: 关闭数据库连接
+//	@Auth shigx 2024-06-05 14:20:11
+//	@return error
+func (s *schemaSource) Close() error {
+	return s.reader.Close()
+}
+
+// selectTables
+//
+//	@Description: 按 --table、--all、--exclude 参数筛选表名，未指定表名时建表语句、快照模式处理全部表
+//	@Auth shigx 2024-06-05 14:20:11
+//	@return []string
+//	@return error
+func (s *schemaSource) selectTables() ([]string, error) {
+	patterns := splitList(viper.GetStringSlice("mysql.table"))
+	excludes := splitList(viper.GetStringSlice("mysql.exclude"))
+	all := s.allTables()
+	if !all && len(patterns) == 0 {
+		return nil, errors.New("请通过 --table 指定表名或使用 --all 处理全部表")
+	}
+
+	ret := make([]string, 0)
+	selected := make(map[string]bool)
+	add := func(name string) {
+		if !selected[name] && !matchAny(name, excludes) {
+			selected[name] = true
+			ret = append(ret, name)
+		}
+	}
+	if all {
+		for _, name := range s.names {
+			add(name)
+		}
+		return ret, nil
+	}
+	for _, pattern := range patterns {
+		// 非通配符的表名直接加入，不存在时在生成阶段报错
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(pattern)
+			continue
+		}
+		for _, name := range s.names {
+			if ok, _ := path.Match(pattern, name); ok {
+				add(name)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// loadTables
+//
+//	@Description: 按参数筛选并加载全部表结构，任一表加载失败时返回错误
+//	@Auth shigx 2024-07-08 14:36:20
+//	@return []*schema.Table
+//	@return error
+func (s *schemaSource) loadTables() ([]*schema.Table, error) {
+	names, err := s.selectTables()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("没有匹配的表")
+	}
+
+	tables := make([]*schema.Table, 0, len(names))
+	for _, name := range names {
+		table, err := s.Table(name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// allTables 是否处理全部表，指定 --all 或建表语句、快照模式未指定表名
+func (s *schemaSource) allTables() bool {
+	return viper.GetBool("mysql.all") || (len(splitList(viper.GetStringSlice("mysql.table"))) == 0 && s.file)
+}
+
+// runTables
+//
+//	@Description: 逐表执行生成操作，单表失败不中断，结束后输出汇总信息
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param action 操作名称，用于输出
+//	@param fn 单表生成函数
+//	@return error 存在失败的表时返回错误
+func (s *schemaSource) runTables(action string, fn func(table *schema.Table) error) error {
+	names, err := s.selectTables()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return errors.New("没有匹配的表")
+	}
+
+	failed := make([]string, 0)
+	for _, name := range names {
+		table, err := s.Table(name)
+		if err == nil {
+			err = fn(table)
+		}
+		if err != nil {
+			failed = append(failed, name)
+			fmt.Printf("table:%s %s失败：%v\n", name, action, err)
+			continue
+		}
+		fmt.Printf("table:%s %s完成\n", name, action)
+	}
+
+	fmt.Printf("%s：共 %d 个表，成功 %d 个，失败 %d 个\n", action, len(names), len(names)-len(failed), len(failed))
+	if len(failed) > 0 {
+		return errors.Errorf("以下表%s失败：%s", action, strings.Join(failed, ","))
+	}
+
+	return nil
+}
+
+// splitList 拆分逗号、空白分隔的列表参数
+func splitList(values []string) []string {
+	ret := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+			ret = append(ret, item)
+		}
+	}
+
+	return ret
+}
+
+// matchAny 判断表名是否匹配任一模式
+func matchAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+// Package cmd
+// @Title 表结构对比
+// @Description 对比两个数据库、建表语句目录或json快照的表结构差异
+// @Author shigx 2024-07-22 15:06:41
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"tool-cli/internal/diff"
+)
+
+var schemaDiffDesc = strings.Join([]string{
+	"对比原表结构与目标表结构，输出从原表结构变更为目标表结构的差异，表结构支持：",
+	"mysql dsn：user@tcp(127.0.0.1:3306)/db",
+	"postgres dsn：postgres://user@127.0.0.1:5432/db?search_path=public",
+	"json快照：sql2md --format json 生成的 schema.json",
+	"建表语句：.sql 文件或目录",
+	"dsn未包含密码时依次使用 --pass-file、环境变量 TOOL_CLI_DB_PASS、MYSQL_PWD 或 PGPASSWORD，连接被拒绝且标准输入为终端时提示输入",
+}, "\n")
+
+var schemaDiffCmd = &cobra.Command{
+	Use:   "diff <source> <target>",
+	Short: "对比两份表结构的差异",
+	Long:  schemaDiffDesc,
+	Args:  cobra.ExactArgs(2),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		_ = viper.BindPFlag("schema.diff.format", cmd.Flags().Lookup("format"))
+		_ = viper.BindPFlag("schema.diff.output", cmd.Flags().Lookup("output"))
+		_ = viper.BindPFlag("schema.diff.exit-code", cmd.Flags().Lookup("exit-code"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		source, err := loadLocation(cmd.Flags(), args[0])
+		cobra.CheckErr(err)
+		target, err := loadLocation(cmd.Flags(), args[1])
+		cobra.CheckErr(err)
+
+		result := diff.Compare(source, target)
+		result.Source, result.Target = schemaName(args[0]), schemaName(args[1])
+		content, err := result.Format(viper.GetString("schema.diff.format"))
+		cobra.CheckErr(err)
+
+		if output := viper.GetString("schema.diff.output"); output != "" {
+			cobra.CheckErr(os.WriteFile(output, []byte(content), 0644))
+		} else {
+			fmt.Print(content)
+		}
+		if viper.GetBool("schema.diff.exit-code") && !result.Empty() {
+			os.Exit(1)
+		}
+	},
+}
+
+// schemaName 返回表结构位置的显示名称，dsn隐藏密码
+func schemaName(location string) string {
+	password, ok := dsnPassword(location)
+	if !ok {
+		return location
+	}
+	at := strings.LastIndex(location, "@")
+
+	return location[:at-len(password)] + "***" + location[at:]
+}
+
+func init() {
+	schemaDiffCmd.Flags().String("format", diff.FormatText, "输出格式，text、markdown、json")
+	schemaDiffCmd.Flags().StringP("output", "o", "", "输出文件，默认输出到标准输出")
+	schemaDiffCmd.Flags().Bool("exit-code", false, "存在差异时以状态码 1 退出")
+	schemaDiffCmd.Flags().String("pass-file", "", "dsn未包含密码时从文件读取db密码，忽略行尾换行")
+	schemaCmd.AddCommand(schemaDiffCmd)
+}
@@ -0,0 +1,68 @@
+// Package cmd
+// @Title 导出表结构快照
+// @Description 将表、字段、索引、外键及备注导出为带版本号的json、yaml快照
+// @Author shigx 2024-08-12 10:08:55
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"tool-cli/internal/schema"
+)
+
+var schemaDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "导出表结构快照",
+	Long:  "导出表结构快照，sql2struct、sql2md、erd 可通过 --from-snapshot 读取快照离线生成，未指定 --table 时导出全部表",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindSourceFlags(cmd)
+		_ = viper.BindPFlag("schema.dump.format", cmd.Flags().Lookup("format"))
+		_ = viper.BindPFlag("schema.dump.output", cmd.Flags().Lookup("output"))
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(splitList(viper.GetStringSlice("mysql.table"))) == 0 {
+			viper.Set("mysql.all", true)
+		}
+		source, err := openSource(cmd)
+		cobra.CheckErr(err)
+		defer func() {
+			// 关闭数据库连接
+			cobra.CheckErr(source.Close())
+		}()
+
+		tables, err := source.loadTables()
+		cobra.CheckErr(err)
+		snapshot := &schema.Schema{
+			Version:  schema.SchemaVersion,
+			Driver:   source.Driver(),
+			Database: source.Database(),
+			Tables:   make([]schema.Table, 0, len(tables)),
+		}
+		for _, table := range tables {
+			snapshot.Tables = append(snapshot.Tables, *table)
+		}
+
+		output := viper.GetString("schema.dump.output")
+		format := viper.GetString("schema.dump.format")
+		if format == "" {
+			format = schema.SnapshotFormat(output)
+		}
+		content, err := schema.EncodeSnapshot(snapshot, format)
+		cobra.CheckErr(err)
+		if output == "" {
+			fmt.Print(string(content))
+			return
+		}
+		cobra.CheckErr(os.WriteFile(output, content, 0644))
+		fmt.Printf("导出表结构快照：共 %d 个表，输出文件：%s\n", len(tables), output)
+	},
+}
+
+func init() {
+	addSourceFlags(schemaDumpCmd)
+	schemaDumpCmd.Flags().String("format", "", "快照格式，json、yaml，默认按输出文件扩展名，标准输出为json")
+	schemaDumpCmd.Flags().StringP("output", "o", "", "输出文件，默认输出到标准输出")
+	schemaCmd.AddCommand(schemaDumpCmd)
+}
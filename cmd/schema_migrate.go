@@ -0,0 +1,94 @@
+// Package cmd
+// @Title 生成迁移sql
+// @Description 对比当前表结构与期望表结构，生成 golang-migrate、goose 迁移文件
+// @Author shigx 2024-07-29 11:20:37
+package cmd
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"tool-cli/internal/migrate"
+	"tool-cli/internal/schema"
+)
+
+var schemaMigrateDesc = strings.Join([]string{
+	"对比当前表结构（线上数据库dsn或json快照）与期望表结构（建表语句文件或目录），生成迁移sql。",
+	"默认拒绝删除表、删除字段、修改字段类型、允许为空改为非空、删除主键等可能丢失数据的变更，需指定 --allow-destructive。",
+	"dsn未包含密码时依次使用 --pass-file、环境变量 TOOL_CLI_DB_PASS、MYSQL_PWD 或 PGPASSWORD，连接被拒绝且标准输入为终端时提示输入。",
+}, "\n")
+
+var schemaMigrateCmd = &cobra.Command{
+	Use:   "migrate <current> <desired>",
+	Short: "生成表结构迁移sql",
+	Long:  schemaMigrateDesc,
+	Args:  cobra.ExactArgs(2),
+	PreRun: func(cmd *cobra.Command, args []string) {
+		for _, name := range []string{"dir", "layout", "name", "version", "down", "allow-destructive"} {
+			_ = viper.BindPFlag("schema.migrate."+name, cmd.Flags().Lookup(name))
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		current, err := loadLocation(cmd.Flags(), args[0])
+		cobra.CheckErr(err)
+		desired, err := loadLocation(cmd.Flags(), args[1])
+		cobra.CheckErr(err)
+		for _, item := range []*schema.Schema{current, desired} {
+			if item.Driver != "" && item.Driver != schema.DriverMySQL {
+				cobra.CheckErr(errors.Errorf("迁移sql只支持mysql表结构，不支持：%s", item.Driver))
+			}
+		}
+
+		up := migrate.Generate(current, desired)
+		if len(up) == 0 {
+			fmt.Println("表结构一致，无需迁移")
+			return
+		}
+		if destructive := migrate.Destructive(up); len(destructive) > 0 && !viper.GetBool("schema.migrate.allow-destructive") {
+			fmt.Print("以下变更可能丢失数据：\n\n" + migrate.Render(destructive) + "\n")
+			cobra.CheckErr(errors.New("存在可能丢失数据的变更，确认后使用 --allow-destructive 生成"))
+		}
+		var down []migrate.Statement
+		if viper.GetBool("schema.migrate.down") {
+			down = migrate.Generate(desired, current)
+		}
+
+		dir := viper.GetString("schema.migrate.dir")
+		if dir == "" {
+			fmt.Print(migrate.Render(up))
+			if down != nil {
+				fmt.Print("\n-- down\n" + migrate.Render(down))
+			}
+			return
+		}
+
+		version := viper.GetString("schema.migrate.version")
+		if version == "" {
+			version = time.Now().Format("20060102150405")
+		}
+		files, err := migrate.Files(viper.GetString("schema.migrate.layout"), version, viper.GetString("schema.migrate.name"), up, down)
+		cobra.CheckErr(err)
+		cobra.CheckErr(os.MkdirAll(dir, 0755))
+		for _, file := range files {
+			fileName := path.Join(dir, file.Name)
+			cobra.CheckErr(os.WriteFile(fileName, []byte(file.Content), 0644))
+			fmt.Printf("生成迁移文件：%s\n", fileName)
+		}
+	},
+}
+
+func init() {
+	schemaMigrateCmd.Flags().String("dir", "", "迁移文件目录，未指定时输出到标准输出")
+	schemaMigrateCmd.Flags().String("layout", migrate.LayoutGolangMigrate, "迁移文件布局，golang-migrate、goose")
+	schemaMigrateCmd.Flags().String("name", "schema", "迁移名称，用于文件名")
+	schemaMigrateCmd.Flags().String("version", "", "迁移版本号，默认为当前时间 yyyymmddhhmmss")
+	schemaMigrateCmd.Flags().Bool("down", false, "同时生成回滚sql")
+	schemaMigrateCmd.Flags().Bool("allow-destructive", false, "允许删除表、删除字段、修改字段类型等可能丢失数据的变更")
+	schemaMigrateCmd.Flags().String("pass-file", "", "dsn未包含密码时从文件读取db密码，忽略行尾换行")
+	schemaCmd.AddCommand(schemaMigrateCmd)
+}
@@ -10,35 +10,29 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"path"
-	"tool-cli/internal/mysql"
+	"strings"
+	"tool-cli/internal/erd"
+	"tool-cli/internal/schema"
 	"tool-cli/internal/sql2md"
+	"tool-cli/internal/tmpl"
 )
 
 var sql2mdCmd = &cobra.Command{
 	Use:   "sql2md",
-	Short: "将mysql表生成md文件",
+	Short: "将mysql表生成md、html、json、csv等格式文档",
 	PreRun: func(cmd *cobra.Command, args []string) {
-		_ = viper.BindPFlag("mysql.addr", cmd.Flags().Lookup("addr"))
-		_ = viper.BindPFlag("mysql.user", cmd.Flags().Lookup("user"))
-		_ = viper.BindPFlag("mysql.pass", cmd.Flags().Lookup("pass"))
-		_ = viper.BindPFlag("mysql.db", cmd.Flags().Lookup("db"))
-		_ = viper.BindPFlag("mysql.table", cmd.Flags().Lookup("table"))
+		bindSourceFlags(cmd)
 		_ = viper.BindPFlag("mysql.dir", cmd.Flags().Lookup("dir"))
+		_ = viper.BindPFlag("sql2md.template", cmd.Flags().Lookup("template"))
+		_ = viper.BindPFlag("sql2md.erd", cmd.Flags().Lookup("erd"))
+		_ = viper.BindPFlag("sql2md.format", cmd.Flags().Lookup("format"))
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		config := &mysql.Config{
-			Addr:     viper.GetString("mysql.addr"),
-			User:     viper.GetString("mysql.user"),
-			Password: viper.GetString("mysql.pass"),
-			DbName:   viper.GetString("mysql.db"),
-		}
-		db, err := mysql.New(config)
-		if err != nil {
-			cobra.CheckErr(err)
-		}
+		source, err := openSource(cmd)
+		cobra.CheckErr(err)
 		defer func() {
 			// 关闭数据库连接
-			cobra.CheckErr(db.CloseDb())
+			cobra.CheckErr(source.Close())
 		}()
 
 		// 检查输出目录是否存在，不存在则创建
@@ -47,33 +41,79 @@ var sql2mdCmd = &cobra.Command{
 			cobra.CheckErr(os.MkdirAll(filePath, 0755))
 		}
 
-		// 查询表备注信息
-		tableComment, err := mysql.GetTableComment(db.GetDb(), viper.GetString("mysql.db"), viper.GetString("mysql.table"))
+		renderer, err := sql2md.NewRenderer(viper.GetString("sql2md.format"), source.Driver())
 		cobra.CheckErr(err)
+		// 自定义模版的文件扩展名取自模版文件名，例：sql2md.html.tpl，否则按文档格式
+		tplFile, ext := templateFile("sql2md.template", "sql2md"), renderer.Ext()
+		if tplExt := tmpl.OutputExt(tplFile); tplExt != "" {
+			ext = tplExt
+		}
+		tables := make([]*schema.Table, 0)
+		err = source.runTables("生成文档", func(table *schema.Table) error {
+			var content string
+			var err error
+			if tplFile != "" {
+				content, err = sql2md.GetMdContentByTemplate(tplFile, source.Database(), table)
+			} else {
+				content, err = renderer.Table(source.Database(), table)
+			}
+			if err != nil {
+				return err
+			}
 
-		tableColumn, err := mysql.GetTableColumn(db.GetDb(), viper.GetString("mysql.db"), viper.GetString("mysql.table"))
+			// 创建文档文件
+			fileName := path.Join(filePath, table.Name+"."+ext)
+			if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+				return err
+			}
+			tables = append(tables, table)
+			return nil
+		})
+		// 处理全部表时生成目录文件，只包含生成成功的表
+		if source.allTables() && len(tables) > 0 {
+			cobra.CheckErr(writeIndex(renderer, ext, filePath, source.Database(), tables))
+		}
 		cobra.CheckErr(err)
-		mdContent := sql2md.GetMdContent(tableColumn, viper.GetString("mysql.db"), viper.GetString("mysql.table"), tableComment)
+	},
+}
 
-		// 创建md文件
-		mdFileName := path.Join(filePath, viper.GetString("mysql.table")+".md")
-		err = os.WriteFile(mdFileName, []byte(mdContent), 0644)
+func init() {
+	addSourceFlags(sql2mdCmd)
+	sql2mdCmd.Flags().String("dir", "./", "请输入输出目录")
+	sql2mdCmd.Flags().String("format", sql2md.FormatMarkdown, "文档格式，"+strings.Join(sql2md.Formats, "、"))
+	sql2mdCmd.Flags().String("erd", "", "处理全部表时在markdown目录文件中嵌入ER图，mermaid、plantuml")
+	sql2mdCmd.Flags().String("template", "", "自定义模版文件，默认使用配置 template.dir 下的 sql2md.tpl，生成文件扩展名取自模版文件名，例：sql2md.html.tpl 生成 .html")
+}
+
+// writeIndex 生成目录文件，自定义模版的扩展名与文档格式不同时，markdown目录链接到模版生成的文件，其他格式的目录无法链接，跳过生成
+func writeIndex(renderer sql2md.Renderer, ext string, dir string, dbName string, tables []*schema.Table) error {
+	var index string
+	switch {
+	case ext == renderer.Ext():
+		content, err := renderer.Index(dbName, tables)
+		if err != nil {
+			return err
+		}
+		index = content
+	case renderer.Ext() == "md":
+		index = sql2md.GetReadmeContent(dbName, tables, ext)
+	default:
+		fmt.Printf("自定义模版生成 .%s 文件，%s 无法链接到表文档，跳过生成目录文件\n", ext, renderer.IndexFile())
+		return nil
+	}
+	if format := viper.GetString("sql2md.erd"); format != "" && renderer.Ext() == "md" {
+		diagram, err := erd.Markdown(format, tables)
 		if err != nil {
-			cobra.CheckErr(err)
+			return err
 		}
+		index += "\n##### ER图\n\n" + diagram
+	}
 
-		fmt.Println("table:" + viper.GetString("mysql.table") + "生成md文件完成")
-	},
-}
+	indexFile := path.Join(dir, renderer.IndexFile())
+	if err := os.WriteFile(indexFile, []byte(index), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("生成目录文件：%s\n", indexFile)
 
-func init() {
-	var (
-		addr, user, pass, db, table, dir string
-	)
-	sql2mdCmd.Flags().StringVar(&addr, "addr", "127.0.0.1:3306", "请输入db地址，例：127.0.0.1:3306")
-	sql2mdCmd.Flags().StringVar(&user, "user", "root", "请输入db用户名")
-	sql2mdCmd.Flags().StringVar(&pass, "pass", "", "请输入db密码")
-	sql2mdCmd.Flags().StringVar(&db, "db", "", "请输入db名称")
-	sql2mdCmd.Flags().StringVar(&table, "table", "", "请输入表名")
-	sql2mdCmd.Flags().StringVar(&dir, "dir", "./", "请输入输出目录")
+	return nil
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/sql2md"
+)
+
+func TestWriteIndexTemplateExt(t *testing.T) {
+	tables := []*schema.Table{{Name: "user"}}
+	tests := []struct {
+		format string
+		ext    string
+		file   string
+		want   string // 为空时不生成目录文件
+	}{
+		{sql2md.FormatMarkdown, "md", "README.md", "[user](user.md)"},
+		// 自定义模版 sql2md.html.tpl：README.md 链接到生成的 .html 文件
+		{sql2md.FormatMarkdown, "html", "README.md", "[user](user.html)"},
+		{sql2md.FormatHTML, "html", "index.html", `<a href="#user">user</a>`},
+		{sql2md.FormatHTML, "txt", "index.html", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+"_"+tt.ext, func(t *testing.T) {
+			renderer, err := sql2md.NewRenderer(tt.format, schema.DriverMySQL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err := writeIndex(renderer, tt.ext, dir, "shop", tables); err != nil {
+				t.Fatalf("writeIndex() error = %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("writeIndex() wrote %s, want skipped", tt.file)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tt.want) {
+				t.Errorf("%s = %s, want it to contain %s", tt.file, content, tt.want)
+			}
+		})
+	}
+}
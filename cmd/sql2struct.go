@@ -9,74 +9,126 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"path"
-	"tool-cli/internal/mysql"
+	"path/filepath"
+	"strings"
+	"tool-cli/internal/schema"
 	"tool-cli/internal/sql2struct"
 )
 
 var sql2structCmd = &cobra.Command{
 	Use:   "sql2struct",
-	Short: "将mysql表生成struct文件",
+	Short: "将mysql、postgres表生成struct文件",
 	PreRun: func(cmd *cobra.Command, args []string) {
-		_ = viper.BindPFlag("mysql.addr", cmd.Flags().Lookup("addr"))
-		_ = viper.BindPFlag("mysql.user", cmd.Flags().Lookup("user"))
-		_ = viper.BindPFlag("mysql.pass", cmd.Flags().Lookup("pass"))
-		_ = viper.BindPFlag("mysql.db", cmd.Flags().Lookup("db"))
-		_ = viper.BindPFlag("mysql.table", cmd.Flags().Lookup("table"))
+		bindSourceFlags(cmd)
 		_ = viper.BindPFlag("mysql.dir", cmd.Flags().Lookup("dir"))
+		_ = viper.BindPFlag("sql2struct.nullable", cmd.Flags().Lookup("nullable"))
+		_ = viper.BindPFlag("sql2struct.tinyint_bool", cmd.Flags().Lookup("tinyint-bool"))
+		_ = viper.BindPFlag("sql2struct.json_type", cmd.Flags().Lookup("json-type"))
+		_ = viper.BindPFlag("sql2struct.decimal_type", cmd.Flags().Lookup("decimal-type"))
+		_ = viper.BindPFlag("sql2struct.tag_dialect", cmd.Flags().Lookup("tag-dialect"))
+		_ = viper.BindPFlag("sql2struct.tags", cmd.Flags().Lookup("tags"))
+		_ = viper.BindPFlag("sql2struct.json_style", cmd.Flags().Lookup("json-style"))
+		_ = viper.BindPFlag("sql2struct.initialisms", cmd.Flags().Lookup("initialisms"))
+		_ = viper.BindPFlag("sql2struct.package", cmd.Flags().Lookup("package"))
+		_ = viper.BindPFlag("sql2struct.trim_prefix", cmd.Flags().Lookup("trim-prefix"))
+		_ = viper.BindPFlag("sql2struct.singular", cmd.Flags().Lookup("singular"))
+		_ = viper.BindPFlag("sql2struct.struct_prefix", cmd.Flags().Lookup("struct-prefix"))
+		_ = viper.BindPFlag("sql2struct.struct_suffix", cmd.Flags().Lookup("struct-suffix"))
+		_ = viper.BindPFlag("sql2struct.template", cmd.Flags().Lookup("template"))
+		_ = viper.BindPFlag("sql2struct.associations", cmd.Flags().Lookup("associations"))
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		config := &mysql.Config{
-			Addr:     viper.GetString("mysql.addr"),
-			User:     viper.GetString("mysql.user"),
-			Password: viper.GetString("mysql.pass"),
-			DbName:   viper.GetString("mysql.db"),
+		config := &sql2struct.Config{
+			Nullable:     viper.GetString("sql2struct.nullable"),
+			TinyintBool:  viper.GetBool("sql2struct.tinyint_bool"),
+			JsonType:     viper.GetString("sql2struct.json_type"),
+			DecimalType:  viper.GetString("sql2struct.decimal_type"),
+			TagDialect:   viper.GetString("sql2struct.tag_dialect"),
+			Tags:         splitList(viper.GetStringSlice("sql2struct.tags")),
+			JsonStyle:    viper.GetString("sql2struct.json_style"),
+			Initialisms:  splitList(viper.GetStringSlice("sql2struct.initialisms")),
+			Package:      viper.GetString("sql2struct.package"),
+			TrimPrefix:   splitList(viper.GetStringSlice("sql2struct.trim_prefix")),
+			Singular:     viper.GetBool("sql2struct.singular"),
+			StructPrefix: viper.GetString("sql2struct.struct_prefix"),
+			StructSuffix: viper.GetString("sql2struct.struct_suffix"),
+			Template:     templateFile("sql2struct.template", "sql2struct"),
+			Associations: viper.GetBool("sql2struct.associations"),
 		}
-		db, err := mysql.New(config)
-		if err != nil {
-			cobra.CheckErr(err)
+		cobra.CheckErr(viper.UnmarshalKey("sql2struct.types", &config.Types))
+		cobra.CheckErr(checkOption("nullable", config.Nullable, sql2struct.NullableNone, sql2struct.NullableSql, sql2struct.NullablePointer, sql2struct.NullableGorm))
+		cobra.CheckErr(checkOption("json-type", config.JsonType, sql2struct.JsonRaw, sql2struct.JsonDatatypes, sql2struct.JsonString))
+		cobra.CheckErr(checkOption("decimal-type", config.DecimalType, sql2struct.DecimalFloat, sql2struct.DecimalString, sql2struct.DecimalDecimal))
+		cobra.CheckErr(checkOption("tag-dialect", config.TagDialect, sql2struct.TagGorm1, sql2struct.TagGorm2, sql2struct.TagNone))
+		cobra.CheckErr(checkOption("json-style", config.JsonStyle, sql2struct.JsonStyleSnake, sql2struct.JsonStyleCamel))
+		for _, tag := range config.Tags {
+			cobra.CheckErr(checkOption("tags", tag, sql2struct.TagJson, sql2struct.TagDb, sql2struct.TagXorm, sql2struct.TagBun, sql2struct.TagForm))
 		}
+
+		source, err := openSource(cmd)
+		cobra.CheckErr(err)
 		defer func() {
 			// 关闭数据库连接
-			cobra.CheckErr(db.CloseDb())
+			cobra.CheckErr(source.Close())
 		}()
+		config.Driver = source.Driver()
 
 		// 检查输出目录是否存在，不存在则创建
 		filePath := viper.GetString("mysql.dir")
+		if config.Package == "" {
+			// 默认使用输出目录名作为包名，同目录下的文件可以一起编译
+			absPath, err := filepath.Abs(filePath)
+			cobra.CheckErr(err)
+			config.Package = sql2struct.PackageName(filepath.Base(absPath))
+		}
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
 			if err := os.MkdirAll(filePath, 0755); err != nil {
 				cobra.CheckErr(err)
 			}
 		}
 
-		// 查询表备注信息
-		tableComment, err := mysql.GetTableComment(db.GetDb(), viper.GetString("mysql.db"), viper.GetString("mysql.table"))
-		cobra.CheckErr(err)
-
-		// 查询表字段信息
-		tableColumn, err := mysql.GetTableColumn(db.GetDb(), viper.GetString("mysql.db"), viper.GetString("mysql.table"))
-		cobra.CheckErr(err)
+		err = source.runTables("生成struct文件", func(table *schema.Table) error {
+			// 创建model文件
+			modelName := path.Join(filePath, table.Name+".go")
 
-		// 创建model文件
-		modelName := path.Join(filePath, viper.GetString("mysql.table")+".go")
+			code, err := sql2struct.GetModelTemplate(table, config)
+			if err != nil {
+				return err
+			}
 
-		code, err := sql2struct.GetModelTemplate(tableColumn, viper.GetString("mysql.table"), tableComment)
+			return os.WriteFile(modelName, code, 0644)
+		})
 		cobra.CheckErr(err)
-
-		cobra.CheckErr(os.WriteFile(modelName, code, 0644))
-
-		fmt.Println("table:" + viper.GetString("mysql.table") + "生成struct文件完成")
 	},
 }
 
 func init() {
-	var (
-		addr, user, password, db, table, out string
-	)
+	addSourceFlags(sql2structCmd)
+	sql2structCmd.Flags().String("dir", "./", "请输入输出目录")
+	sql2structCmd.Flags().String("nullable", sql2struct.NullableNone, "可空字段处理方式：none 普通类型、sql 使用sql.NullXxx、pointer 使用指针、gorm 使用datatypes.NullXxx")
+	sql2structCmd.Flags().Bool("tinyint-bool", false, "tinyint(1) 字段生成 bool 类型")
+	sql2structCmd.Flags().String("json-type", sql2struct.JsonRaw, "json字段类型：raw 使用json.RawMessage、datatypes 使用datatypes.JSON、string")
+	sql2structCmd.Flags().String("decimal-type", sql2struct.DecimalFloat, "decimal字段类型：float64、string、decimal 使用shopspring/decimal")
+	sql2structCmd.Flags().String("tag-dialect", sql2struct.TagGorm1, "gorm标签风格：gorm1、gorm2、none 不输出")
+	sql2structCmd.Flags().StringSlice("tags", nil, "额外输出的标签，多个用逗号分隔：json、db、xorm、bun、form")
+	sql2structCmd.Flags().String("json-style", sql2struct.JsonStyleSnake, "json、form标签命名风格：snake、camel")
+	sql2structCmd.Flags().StringSlice("initialisms", nil, "自定义缩写词，多个用逗号分隔，生成字段名时全部大写，例：SKU,OSS")
+	sql2structCmd.Flags().String("package", "", "生成文件的包名，默认使用输出目录名")
+	sql2structCmd.Flags().StringSlice("trim-prefix", nil, "生成结构体名时去除的表前缀，多个用逗号分隔，例：t_")
+	sql2structCmd.Flags().Bool("singular", false, "结构体名转为单数，例：users => User")
+	sql2structCmd.Flags().String("struct-prefix", "", "结构体名前缀")
+	sql2structCmd.Flags().String("struct-suffix", "", "结构体名后缀，例：Model")
+	sql2structCmd.Flags().Bool("associations", false, "按外键生成 BelongsTo、HasMany 关联字段，关联的表需生成在同一目录")
+	sql2structCmd.Flags().String("template", "", "自定义模版文件，默认使用配置 template.dir 下的 sql2struct.tpl")
+}
+
+// checkOption 检查参数取值是否合法
+func checkOption(name string, value string, allowed ...string) error {
+	for _, item := range allowed {
+		if value == item {
+			return nil
+		}
+	}
 
-	sql2structCmd.Flags().StringVar(&addr, "addr", "127.0.0.1:3306", "请输入db地址，例：127.0.0.1:3306")
-	sql2structCmd.Flags().StringVar(&user, "user", "root", "请输入db用户名")
-	sql2structCmd.Flags().StringVar(&password, "pass", "", "请输入db密码")
-	sql2structCmd.Flags().StringVar(&db, "db", "", "请输入db名称")
-	sql2structCmd.Flags().StringVar(&table, "table", "", "请输入表名")
-	sql2structCmd.Flags().StringVar(&out, "dir", "./", "请输入输出目录")
+	return fmt.Errorf("参数 %s 不支持的取值：%s，可选：%s", name, value, strings.Join(allowed, "、"))
 }
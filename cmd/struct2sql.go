@@ -0,0 +1,81 @@
+// Package cmd
+// @Title 将go结构体生成建表语句
+// @Description 解析带gorm标签的结构体，生成mysql建表语句或迁移文件
+// @Author shigx 2024-08-05 16:12:48
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"path"
+	"time"
+	"tool-cli/internal/migrate"
+	"tool-cli/internal/struct2sql"
+)
+
+var struct2sqlCmd = &cobra.Command{
+	Use:   "struct2sql",
+	Short: "将go结构体生成mysql建表语句",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		for _, name := range []string{"input", "struct", "singular", "output", "dir", "layout", "name", "version"} {
+			_ = viper.BindPFlag("struct2sql."+name, cmd.Flags().Lookup(name))
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		config := &struct2sql.Config{
+			Structs:  splitList(viper.GetStringSlice("struct2sql.struct")),
+			Singular: viper.GetBool("struct2sql.singular"),
+		}
+		tables, err := struct2sql.Parse(viper.GetString("struct2sql.input"), config)
+		cobra.CheckErr(err)
+		if len(tables) == 0 {
+			fmt.Println("没有找到带gorm标签的结构体")
+			return
+		}
+
+		up := make([]migrate.Statement, 0, len(tables))
+		down := make([]migrate.Statement, 0, len(tables))
+		for k := range tables {
+			up = append(up, migrate.Statement{SQL: migrate.CreateTable(&tables[k])})
+			down = append([]migrate.Statement{{SQL: fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", tables[k].Name)}}, down...)
+		}
+
+		// 生成迁移文件
+		if dir := viper.GetString("struct2sql.dir"); dir != "" {
+			version := viper.GetString("struct2sql.version")
+			if version == "" {
+				version = time.Now().Format("20060102150405")
+			}
+			files, err := migrate.Files(viper.GetString("struct2sql.layout"), version, viper.GetString("struct2sql.name"), up, down)
+			cobra.CheckErr(err)
+			cobra.CheckErr(os.MkdirAll(dir, 0755))
+			for _, file := range files {
+				fileName := path.Join(dir, file.Name)
+				cobra.CheckErr(os.WriteFile(fileName, []byte(file.Content), 0644))
+				fmt.Printf("生成迁移文件：%s\n", fileName)
+			}
+			return
+		}
+
+		content := migrate.Render(up)
+		if output := viper.GetString("struct2sql.output"); output != "" {
+			cobra.CheckErr(os.WriteFile(output, []byte(content), 0644))
+			fmt.Printf("生成建表语句：共 %d 个表，输出文件：%s\n", len(tables), output)
+			return
+		}
+		fmt.Print(content)
+	},
+}
+
+func init() {
+	struct2sqlCmd.Flags().StringP("input", "i", ".", "go文件或目录，目录时处理其中全部go文件")
+	struct2sqlCmd.Flags().StringSlice("struct", nil, "只处理指定的结构体，默认处理全部带gorm标签或TableName方法的结构体")
+	struct2sqlCmd.Flags().Bool("singular", false, "没有TableName方法时表名不转为复数")
+	struct2sqlCmd.Flags().StringP("output", "o", "", "输出文件，默认输出到标准输出")
+	struct2sqlCmd.Flags().String("dir", "", "生成迁移文件的目录，指定后按 --layout 生成迁移文件")
+	struct2sqlCmd.Flags().String("layout", migrate.LayoutGolangMigrate, "迁移文件布局，golang-migrate、goose")
+	struct2sqlCmd.Flags().String("name", "create_tables", "迁移名称，用于文件名")
+	struct2sqlCmd.Flags().String("version", "", "迁移版本号，默认为当前时间 yyyymmddhhmmss")
+}
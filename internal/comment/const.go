@@ -9,27 +9,27 @@ import (
 	"go/ast"
 	"go/format"
 	"strings"
-	"text/template"
+	"tool-cli/internal/tmpl"
 )
 
 const tpl = `// Code generated by tool-cli DO NOT EDIT
-// Package {{.pkg}} const code comment msg
-package {{.pkg}}
+// Package {{.Package}} const code comment msg
+package {{.Package}}
 // noMsg if code is not found, GetMsg will return this
 const noMsg = "unknown"
 // messages get msg from const comment
-var messages = map[{{.constType}}]string{
-	{{range $key, $value := .comments}}
+var messages = map[{{.ConstType}}]string{
+	{{range $key, $value := .Comments}}
 	{{$key}}: "{{$value}}",{{end}}
 }
-{{ if ne .constType "int" }}
+{{ if ne .ConstType "int" }}
 // String return string
-func (code {{.constType}}) String () string {
+func (code {{.ConstType}}) String () string {
 	return GetMsg(code)
 }
 {{ end }}
 // GetMsg get error msg
-func GetMsg(code {{.constType}}) string {
+func GetMsg(code {{.ConstType}}) string {
 	var (
 		msg string
 		ok  bool
@@ -40,6 +40,15 @@ func GetMsg(code {{.constType}}) string {
 	return msg
 }`
 
+// ConData @Description 模版数据，自定义模版可使用全部字段及 tmpl.FuncMap 中的函数
+// @Auth shigx
+// @Date 2024-06-24 10:30:52
+type ConData struct {
+	Package   string            // 包名
+	ConstType string            // 常量类型
+	Comments  map[string]string // 常量名 => 注释
+}
+
 // @Description 注释处理
 // @Auth shigx
 // @Date 2021/10/28 9:58 上午
@@ -64,21 +73,18 @@ func GetConComment(group *ast.CommentGroup) string {
 // @param constType string 常量类型
 // @param pkg string 包名
 // @param comments 常量注释信息
+// @param file 自定义模版文件，为空时使用默认模版
 // @return
-func GetConCode(constType string, pkg string, comments map[string]string) ([]byte, error) {
-	var (
-		t   *template.Template
-		err error
-		buf = bytes.NewBufferString("")
-	)
-	data := map[string]interface{}{
-		"pkg":       pkg,
-		"comments":  comments,
-		"constType": constType,
+func GetConCode(constType string, pkg string, comments map[string]string, file string) ([]byte, error) {
+	buf := bytes.NewBufferString("")
+	data := &ConData{
+		Package:   pkg,
+		ConstType: constType,
+		Comments:  comments,
 	}
-	t, err = template.New("").Parse(tpl)
+	t, err := tmpl.Parse("", file, tpl)
 	if err != nil {
-		return nil, errors.Wrap(err, "template init err")
+		return nil, err
 	}
 	err = t.Execute(buf, data)
 	if err != nil {
@@ -0,0 +1,214 @@
+// Package datasource
+// @Description: 表结构来源，按驱动连接mysql、postgresql、sqlite数据库，或读取建表语句、快照
+// @Auth shigx 2024-08-19 14:26:37
+package datasource
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/sqlite"
+)
+
+// Config @Description 数据库连接配置
+// @Auth shigx
+// @Date 2024-08-19 14:26:37
+type Config struct {
+	Driver   string // 数据库驱动，见 schema.Driver* 常量，默认mysql
+	Addr     string // 数据库链接地址，为空时使用驱动的默认地址
+	User     string // 用户名，为空时使用驱动的默认用户
+	Password string // 密码
+	DbName   string // 数据库名
+	Schema   string // postgresql的schema，默认public
+	Dsn      string // 完整dsn，指定后忽略地址、用户名、密码、数据库名，sqlite为数据库文件路径
+	// mysql连接选项，见 mysql.Config
+	Socket       string
+	TLS          string
+	TLSCa        string
+	TLSCert      string
+	TLSKey       string
+	Timeout      time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	Params       map[string]string
+}
+
+// Open
+//
+//	@Description: 按驱动连接数据库
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param config
+//	@return schema.Reader
+//	@return error
+func Open(config *Config) (schema.Reader, error) {
+	switch config.Driver {
+	case schema.DriverMySQL, "":
+		return openMysql(config)
+	case schema.DriverPostgres:
+		if config.Dsn != "" {
+			return openPostgresDsn(config.Dsn)
+		}
+		return openPostgres(config)
+	case schema.DriverSqlite:
+		if config.Dsn == "" {
+			return nil, errors.New("sqlite需要通过 --dsn 指定数据库文件")
+		}
+		return openSqlite(config.Dsn)
+	}
+
+	return nil, errors.Errorf("不支持的数据库驱动：%s，可选值：%s", config.Driver, strings.Join(schema.Drivers, "、"))
+}
+
+// OpenDsn
+//
+//	@Description: 使用dsn连接数据库，postgres:// 或 postgresql:// 开头的为postgresql，sqlite数据库文件为sqlite，其余为mysql
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param dsn
+//	@return schema.Reader
+//	@return error
+func OpenDsn(dsn string) (schema.Reader, error) {
+	switch {
+	case isPostgresDsn(dsn):
+		return openPostgresDsn(dsn)
+	case isSqliteFile(dsn):
+		return openSqlite(dsn)
+	}
+
+	return openMysqlDsn(dsn)
+}
+
+// LoadLocation
+//
+//	@Description: 按位置加载整库表结构：.json、.yaml、.yml 文件为快照，.db、.sqlite、.sqlite3 文件为sqlite数据库，
+//	postgres:// 开头或 user@tcp(host:port)/db 格式的为dsn（见 DsnDriver），其余为建表语句文件或目录
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param location
+//	@return *schema.Schema
+//	@return error
+func LoadLocation(location string) (*schema.Schema, error) {
+	switch {
+	case isSnapshot(location):
+		return schema.ReadSnapshot(location)
+	case DsnDriver(location) != "":
+		reader, err := OpenDsn(location)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		return schema.Load(reader)
+	}
+
+	tables, err := mysql.ReadDdl(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.Load(schema.NewStatic(schema.DriverMySQL, "", tables))
+}
+
+// DsnDriver 返回dsn对应的数据库驱动，postgres:// 或 postgresql:// 开头的为postgresql，sqlite数据库文件为sqlite，
+// go-sql-driver格式的为mysql，不是dsn时返回空
+func DsnDriver(location string) string {
+	switch {
+	case isPostgresDsn(location):
+		return schema.DriverPostgres
+	case isSqliteFile(location):
+		return schema.DriverSqlite
+	case isMysqlDsn(location):
+		return schema.DriverMySQL
+	}
+
+	return ""
+}
+
+// SetDsnPassword
+//
+//	@Description: 设置mysql、postgresql dsn中的密码，用于dsn中未包含密码、密码来自密码文件或环境变量的场景
+//	@Auth shigx 2024-09-16 15:40:12
+//	@param dsn
+//	@param password
+//	@return string
+//	@return error
+func SetDsnPassword(dsn string, password string) (string, error) {
+	switch DsnDriver(dsn) {
+	case schema.DriverPostgres:
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", errors.Wrap(err, "dsn格式错误")
+		}
+		u.User = url.UserPassword(u.User.Username(), password)
+		return u.String(), nil
+	case schema.DriverMySQL:
+		cfg, err := driver.ParseDSN(dsn)
+		if err != nil {
+			return "", errors.Wrap(err, "dsn格式错误")
+		}
+		cfg.Passwd = password
+		return cfg.FormatDSN(), nil
+	}
+
+	return dsn, nil
+}
+
+// IsAccessDenied 是否用户名或密码错误导致的连接失败，mysql 1045、1698，postgresql 28P01
+func IsAccessDenied(err error) bool {
+	var mysqlErr *driver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1045 || mysqlErr.Number == 1698
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "28P01"
+	}
+
+	return false
+}
+
+// isSnapshot 是否快照文件
+func isSnapshot(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+
+	return false
+}
+
+// isSqliteFile 是否sqlite数据库文件，按扩展名判断，支持 file: 开头的uri
+func isSqliteFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(sqlite.FileName(path))) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	}
+
+	return strings.HasPrefix(path, "file:")
+}
+
+// mysqlDsnAddr 匹配go-sql-driver格式dsn最后一个 @ 之后的部分，例：tcp(127.0.0.1:3306)/db、unix(/tmp/mysql.sock)/db、/db
+var mysqlDsnAddr = regexp.MustCompile(`^(\w+\([^)]*\))?/`)
+
+// isMysqlDsn 是否go-sql-driver格式的mysql dsn，例：user:pass@tcp(127.0.0.1:3306)/db；磁盘上已存在的文件、目录不是dsn，例：./ddl@v2/
+func isMysqlDsn(location string) bool {
+	at := strings.LastIndex(location, "@")
+	if at < 0 || !mysqlDsnAddr.MatchString(location[at+1:]) {
+		return false
+	}
+	_, err := os.Stat(location)
+
+	return err != nil
+}
+
+// isPostgresDsn 是否postgresql的url格式dsn
+func isPostgresDsn(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
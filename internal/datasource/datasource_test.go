@@ -0,0 +1,95 @@
+package datasource
+
+import (
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"tool-cli/internal/schema"
+)
+
+func TestSetDsnPassword(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"app@tcp(db:3306)/shop", "app:p@ss@tcp(db:3306)/shop"},
+		{"app:old@tcp(db:3306)/shop?collation=utf8mb4_bin", "app:p@ss@tcp(db:3306)/shop?collation=utf8mb4_bin"},
+		{"postgres://app@db:5432/shop?search_path=app", "postgres://app:p%40ss@db:5432/shop?search_path=app"},
+		{"./shop.db", "./shop.db"},
+	}
+	for _, tt := range tests {
+		got, err := SetDsnPassword(tt.dsn, "p@ss")
+		if err != nil {
+			t.Fatalf("SetDsnPassword(%q) error = %v", tt.dsn, err)
+		}
+		if got != tt.want {
+			t.Errorf("SetDsnPassword(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestDsnDriver(t *testing.T) {
+	tests := map[string]string{
+		"app@tcp(db:3306)/shop":               schema.DriverMySQL,
+		"app:p@ss@unix(/tmp/mysql.sock)/shop": schema.DriverMySQL,
+		"app@/shop":                           schema.DriverMySQL,
+		"postgres://app@db/shop":              schema.DriverPostgres,
+		"./shop.sqlite":                       schema.DriverSqlite,
+		"./ddl":                               "",
+		"./ddl@v2/":                           "",
+		"./ddl@v2/shop.sql":                   "",
+		"schema.json":                         "",
+	}
+	for location, want := range tests {
+		if got := DsnDriver(location); got != want {
+			t.Errorf("DsnDriver(%q) = %q, want %q", location, got, want)
+		}
+	}
+}
+
+func TestIsAccessDenied(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.Wrap(&driver.MySQLError{Number: 1045, Message: "Access denied"}, "[db connection failed]"), true},
+		{&driver.MySQLError{Number: 1049, Message: "Unknown database"}, false},
+		{errors.Wrap(&pgconn.PgError{Code: "28P01"}, "[db connection failed]"), true},
+		{&pgconn.PgError{Code: "3D000"}, false},
+		{errors.New("dial tcp: connection refused"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsAccessDenied(tt.err); got != tt.want {
+			t.Errorf("IsAccessDenied(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestLoadLocationPathWithAt(t *testing.T) {
+	base := t.TempDir()
+	ddl := "CREATE TABLE `user` (`id` bigint NOT NULL AUTO_INCREMENT, PRIMARY KEY (`id`));"
+	// ddl@/v2 符合 user@/db 的dsn格式，目录存在时按建表语句读取
+	for _, name := range []string{"ddl@v2", "ddl@/v2"} {
+		dir := filepath.Join(base, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "user.sql"), []byte(ddl), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if driver := DsnDriver(dir); driver != "" {
+			t.Errorf("DsnDriver(%q) = %q, want directory", dir, driver)
+		}
+		ret, err := LoadLocation(dir)
+		if err != nil {
+			t.Fatalf("LoadLocation(%q) error = %v", dir, err)
+		}
+		if len(ret.Tables) != 1 || ret.Tables[0].Name != "user" {
+			t.Errorf("LoadLocation(%q) tables = %+v", dir, ret.Tables)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package datasource
+// @Description: mysql表结构读取
+// @Auth shigx 2024-08-19 14:26:37
+package datasource
+
+import (
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+var _ schema.Reader = (*mysqlReader)(nil)
+
+// mysqlReader 从 information_schema 读取mysql表结构
+type mysqlReader struct {
+	repo   mysql.Repo
+	dbName string
+}
+
+// openMysql 按配置连接mysql，指定dsn时数据库名取自dsn
+func openMysql(config *Config) (schema.Reader, error) {
+	addr, user := config.Addr, config.User
+	if addr == "" {
+		addr = "127.0.0.1:3306"
+	}
+	if user == "" {
+		user = "root"
+	}
+	mysqlConfig := &mysql.Config{
+		Addr:         addr,
+		User:         user,
+		Password:     config.Password,
+		DbName:       config.DbName,
+		Dsn:          config.Dsn,
+		Socket:       config.Socket,
+		TLS:          config.TLS,
+		TLSCa:        config.TLSCa,
+		TLSCert:      config.TLSCert,
+		TLSKey:       config.TLSKey,
+		Timeout:      config.Timeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		Params:       config.Params,
+	}
+	repo, dbName, err := mysql.New(mysqlConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqlReader{repo: repo, dbName: dbName}, nil
+}
+
+// openMysqlDsn 使用dsn连接mysql
+func openMysqlDsn(dsn string) (schema.Reader, error) {
+	repo, dbName, err := mysql.NewWithDsn(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mysqlReader{repo: repo, dbName: dbName}, nil
+}
+
+func (r *mysqlReader) Driver() string {
+	return schema.DriverMySQL
+}
+
+func (r *mysqlReader) Database() string {
+	return r.dbName
+}
+
+func (r *mysqlReader) TableNames() ([]string, error) {
+	return mysql.GetTableNames(r.repo.GetDb(), r.dbName)
+}
+
+func (r *mysqlReader) Table(name string) (*schema.Table, error) {
+	return mysql.GetTable(r.repo.GetDb(), r.dbName, name)
+}
+
+func (r *mysqlReader) Close() error {
+	return r.repo.CloseDb()
+}
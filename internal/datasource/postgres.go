@@ -0,0 +1,73 @@
+// Package datasource
+// @Description: postgresql表结构读取
+// @Auth shigx 2024-08-19 14:26:37
+package datasource
+
+import (
+	"tool-cli/internal/postgres"
+	"tool-cli/internal/schema"
+)
+
+var _ schema.Reader = (*postgresReader)(nil)
+
+// postgresReader 从 pg_catalog 读取postgresql表结构，只读取指定schema中的表
+type postgresReader struct {
+	repo   postgres.Repo
+	dbName string
+	schema string
+}
+
+// openPostgres 按配置连接postgresql
+func openPostgres(config *Config) (schema.Reader, error) {
+	addr, user, schemaName := config.Addr, config.User, config.Schema
+	if addr == "" {
+		addr = "127.0.0.1:5432"
+	}
+	if user == "" {
+		user = "postgres"
+	}
+	if schemaName == "" {
+		schemaName = postgres.DefaultSchema
+	}
+	repo, err := postgres.New(&postgres.Config{
+		Addr:     addr,
+		User:     user,
+		Password: config.Password,
+		DbName:   config.DbName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresReader{repo: repo, dbName: config.DbName, schema: schemaName}, nil
+}
+
+// openPostgresDsn 使用dsn连接postgresql，schema取自dsn中的 search_path
+func openPostgresDsn(dsn string) (schema.Reader, error) {
+	repo, dbName, schemaName, err := postgres.NewWithDsn(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &postgresReader{repo: repo, dbName: dbName, schema: schemaName}, nil
+}
+
+func (r *postgresReader) Driver() string {
+	return schema.DriverPostgres
+}
+
+func (r *postgresReader) Database() string {
+	return r.dbName
+}
+
+func (r *postgresReader) TableNames() ([]string, error) {
+	return postgres.GetTableNames(r.repo.GetDb(), r.schema)
+}
+
+func (r *postgresReader) Table(name string) (*schema.Table, error) {
+	return postgres.GetTable(r.repo.GetDb(), r.schema, name)
+}
+
+func (r *postgresReader) Close() error {
+	return r.repo.CloseDb()
+}
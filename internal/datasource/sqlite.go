@@ -0,0 +1,58 @@
+// Package datasource
+// @Description: sqlite表结构读取
+// @Auth shigx 2024-08-26 10:15:42
+package datasource
+
+import (
+	"strings"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/sqlite"
+)
+
+var _ schema.Reader = (*sqliteReader)(nil)
+
+// sqliteReader 从 PRAGMA 及 sqlite_master 读取sqlite表结构
+type sqliteReader struct {
+	repo   sqlite.Repo
+	dbName string
+	// 被引用的表名（小写） => 引用该表的外键，首次读取表时加载
+	referencedBy map[string][]schema.ForeignKey
+}
+
+// openSqlite 打开sqlite数据库文件
+func openSqlite(dsn string) (schema.Reader, error) {
+	repo, err := sqlite.New(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqliteReader{repo: repo, dbName: sqlite.DbName(dsn)}, nil
+}
+
+func (r *sqliteReader) Driver() string {
+	return schema.DriverSqlite
+}
+
+func (r *sqliteReader) Database() string {
+	return r.dbName
+}
+
+func (r *sqliteReader) TableNames() ([]string, error) {
+	return sqlite.GetTableNames(r.repo.GetDb())
+}
+
+func (r *sqliteReader) Table(name string) (*schema.Table, error) {
+	if r.referencedBy == nil {
+		referencedBy, err := sqlite.GetReferencingForeignKeys(r.repo.GetDb())
+		if err != nil {
+			return nil, err
+		}
+		r.referencedBy = referencedBy
+	}
+
+	return sqlite.GetTable(r.repo.GetDb(), name, r.referencedBy[strings.ToLower(name)])
+}
+
+func (r *sqliteReader) Close() error {
+	return r.repo.CloseDb()
+}
@@ -0,0 +1,281 @@
+// Package diff
+// @Description: 对比两份表结构，输出表、字段、索引、外键的差异
+// @Auth shigx 2024-07-22 15:06:41
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 差异类型
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionChanged = "changed"
+)
+
+// 差异对象
+const (
+	ObjectTable      = "table"
+	ObjectColumn     = "column"
+	ObjectIndex      = "index"
+	ObjectForeignKey = "foreign_key"
+)
+
+// Result @Description 表结构差异，表按表名排序
+// @Auth shigx
+// @Date 2024-07-22 15:06:41
+type Result struct {
+	Source string  `json:"source"` // 原表结构
+	Target string  `json:"target"` // 目标表结构
+	Tables []Table `json:"tables"` // 存在差异的表
+}
+
+// Table @Description 单表差异
+// @Auth shigx
+// @Date 2024-07-22 15:06:41
+type Table struct {
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`            // added、removed、changed
+	Changes []Change `json:"changes,omitempty"` // 表修改时的明细，按表属性、字段、索引、外键排序
+	// 新增、删除的表结构，修改时为目标表结构
+	Table *schema.Table `json:"-"`
+}
+
+// Change @Description 表内对象差异，修改时 Attribute 为修改的属性
+// @Auth shigx
+// @Date 2024-07-22 15:06:41
+type Change struct {
+	Object    string `json:"object"` // table、column、index、foreign_key
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+	Attribute string `json:"attribute,omitempty"` // 修改的属性，例：type、default、nullable
+	Old       string `json:"old,omitempty"`
+	New       string `json:"new,omitempty"`
+	// 新增、删除对象的定义，例：varchar(32) NOT NULL
+	Definition string `json:"definition,omitempty"`
+}
+
+// Empty 是否没有差异
+func (r *Result) Empty() bool {
+	return len(r.Tables) == 0
+}
+
+// Compare
+//
+//	@Description: 对比原表结构与目标表结构，差异描述为从原表结构变更为目标表结构
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param source
+//	@param target
+//	@return *Result
+func Compare(source *schema.Schema, target *schema.Schema) *Result {
+	ret := &Result{Tables: make([]Table, 0)}
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, s := range []*schema.Schema{source, target} {
+		for _, table := range s.Tables {
+			if !seen[table.Name] {
+				seen[table.Name] = true
+				names = append(names, table.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		old, cur := source.Table(name), target.Table(name)
+		switch {
+		case old == nil:
+			ret.Tables = append(ret.Tables, Table{Name: name, Action: ActionAdded, Table: cur})
+		case cur == nil:
+			ret.Tables = append(ret.Tables, Table{Name: name, Action: ActionRemoved, Table: old})
+		default:
+			if changes := compareTable(old, cur); len(changes) > 0 {
+				ret.Tables = append(ret.Tables, Table{Name: name, Action: ActionChanged, Changes: changes, Table: cur})
+			}
+		}
+	}
+
+	return ret
+}
+
+// compareTable 对比表备注、字段、索引及外键
+func compareTable(old *schema.Table, cur *schema.Table) []Change {
+	changes := make([]Change, 0)
+	if old.Comment != cur.Comment {
+		changes = append(changes, Change{Object: ObjectTable, Name: cur.Name, Action: ActionChanged, Attribute: "comment", Old: old.Comment, New: cur.Comment})
+	}
+	changes = append(changes, compareColumns(old.Columns, cur.Columns)...)
+	changes = append(changes, compareIndexes(old.Indexes, cur.Indexes)...)
+	changes = append(changes, compareForeignKeys(old.ForeignKeys, cur.ForeignKeys)...)
+
+	return changes
+}
+
+// compareColumns 对比字段，新增、修改按目标表字段顺序，删除的字段排在最后
+func compareColumns(old []schema.TableColumn, cur []schema.TableColumn) []Change {
+	oldColumns := make(map[string]schema.TableColumn, len(old))
+	for _, column := range old {
+		oldColumns[column.ColumnName] = column
+	}
+
+	changes := make([]Change, 0)
+	curColumns := make(map[string]bool, len(cur))
+	for _, column := range cur {
+		curColumns[column.ColumnName] = true
+		oldColumn, ok := oldColumns[column.ColumnName]
+		if !ok {
+			changes = append(changes, Change{Object: ObjectColumn, Name: column.ColumnName, Action: ActionAdded, Definition: ColumnDefinition(column)})
+			continue
+		}
+		for _, attr := range columnAttributes {
+			if o, n := attr.value(oldColumn), attr.value(column); o != n {
+				changes = append(changes, Change{Object: ObjectColumn, Name: column.ColumnName, Action: ActionChanged, Attribute: attr.name, Old: o, New: n})
+			}
+		}
+	}
+	for _, column := range old {
+		if !curColumns[column.ColumnName] {
+			changes = append(changes, Change{Object: ObjectColumn, Name: column.ColumnName, Action: ActionRemoved, Definition: ColumnDefinition(column)})
+		}
+	}
+
+	return changes
+}
+
+// columnAttributes 对比的字段属性
+var columnAttributes = []struct {
+	name  string
+	value func(column schema.TableColumn) string
+}{
+	{"type", func(column schema.TableColumn) string { return column.ColumnType }},
+	{"nullable", func(column schema.TableColumn) string { return column.IsNullable }},
+	{"default", func(column schema.TableColumn) string { return defaultText(column) }},
+	{"extra", func(column schema.TableColumn) string { return column.Extra() }},
+	{"comment", func(column schema.TableColumn) string { return column.ColumnComment.String }},
+}
+
+// compareIndexes 对比索引，按索引名匹配
+func compareIndexes(old []schema.TableIndex, cur []schema.TableIndex) []Change {
+	oldIndexes := make(map[string]schema.TableIndex, len(old))
+	for _, index := range old {
+		oldIndexes[index.Name] = index
+	}
+
+	changes := make([]Change, 0)
+	curIndexes := make(map[string]bool, len(cur))
+	for _, index := range cur {
+		curIndexes[index.Name] = true
+		oldIndex, ok := oldIndexes[index.Name]
+		if !ok {
+			changes = append(changes, Change{Object: ObjectIndex, Name: index.Name, Action: ActionAdded, Definition: IndexDefinition(index)})
+			continue
+		}
+		if o, n := IndexDefinition(oldIndex), IndexDefinition(index); o != n {
+			changes = append(changes, Change{Object: ObjectIndex, Name: index.Name, Action: ActionChanged, Attribute: "definition", Old: o, New: n})
+		}
+		if oldIndex.Comment != index.Comment {
+			changes = append(changes, Change{Object: ObjectIndex, Name: index.Name, Action: ActionChanged, Attribute: "comment", Old: oldIndex.Comment, New: index.Comment})
+		}
+	}
+	for _, index := range old {
+		if !curIndexes[index.Name] {
+			changes = append(changes, Change{Object: ObjectIndex, Name: index.Name, Action: ActionRemoved, Definition: IndexDefinition(index)})
+		}
+	}
+
+	return changes
+}
+
+// compareForeignKeys 对比外键，按约束名匹配
+func compareForeignKeys(old []schema.ForeignKey, cur []schema.ForeignKey) []Change {
+	oldKeys := make(map[string]schema.ForeignKey, len(old))
+	for _, fk := range old {
+		oldKeys[fk.Name] = fk
+	}
+
+	changes := make([]Change, 0)
+	curKeys := make(map[string]bool, len(cur))
+	for _, fk := range cur {
+		curKeys[fk.Name] = true
+		oldKey, ok := oldKeys[fk.Name]
+		if !ok {
+			changes = append(changes, Change{Object: ObjectForeignKey, Name: fk.Name, Action: ActionAdded, Definition: ForeignKeyDefinition(fk)})
+			continue
+		}
+		if o, n := ForeignKeyDefinition(oldKey), ForeignKeyDefinition(fk); o != n {
+			changes = append(changes, Change{Object: ObjectForeignKey, Name: fk.Name, Action: ActionChanged, Attribute: "definition", Old: o, New: n})
+		}
+	}
+	for _, fk := range old {
+		if !curKeys[fk.Name] {
+			changes = append(changes, Change{Object: ObjectForeignKey, Name: fk.Name, Action: ActionRemoved, Definition: ForeignKeyDefinition(fk)})
+		}
+	}
+
+	return changes
+}
+
+// ColumnDefinition 返回字段定义描述，例：varchar(32) NOT NULL DEFAULT '' COMMENT '名称'
+func ColumnDefinition(column schema.TableColumn) string {
+	parts := []string{column.ColumnType}
+	if column.IsNullable == "NO" {
+		parts = append(parts, "NOT NULL")
+	}
+	if column.ColumnDefault.Valid {
+		parts = append(parts, "DEFAULT "+defaultText(column))
+	}
+	if extra := column.Extra(); extra != "" {
+		parts = append(parts, extra)
+	}
+	if column.ColumnComment.String != "" {
+		parts = append(parts, "COMMENT "+quote(column.ColumnComment.String))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// IndexDefinition 返回索引定义描述，例：UNIQUE BTREE (name(10), age)、BTREE ((lower(name)))
+func IndexDefinition(index schema.TableIndex) string {
+	columns := make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		columns = append(columns, column.String())
+	}
+	kind := index.Type
+	if index.Unique && !index.IsPrimary() {
+		kind = "UNIQUE " + kind
+	}
+
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(columns, ", "))
+}
+
+// ForeignKeyDefinition 返回外键定义描述，例：(user_id) REFERENCES user (id) ON UPDATE NO ACTION ON DELETE CASCADE
+func ForeignKeyDefinition(fk schema.ForeignKey) string {
+	return fmt.Sprintf("(%s) REFERENCES %s (%s) ON UPDATE %s ON DELETE %s",
+		strings.Join(fk.Columns, ", "),
+		fk.ReferencedTable,
+		strings.Join(fk.ReferencedColumns, ", "),
+		fk.OnUpdate,
+		fk.OnDelete,
+	)
+}
+
+// defaultText 返回默认值描述，null与空字符串区分，表达式不加引号
+func defaultText(column schema.TableColumn) string {
+	if !column.ColumnDefault.Valid {
+		return "NULL"
+	}
+	if column.DefaultExpr {
+		return column.ColumnDefault.String
+	}
+
+	return quote(column.ColumnDefault.String)
+}
+
+// quote 使用单引号包裹字符串
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
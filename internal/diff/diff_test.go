@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// parseSchema 解析建表语句为表结构
+func parseSchema(t *testing.T, ddl string) *schema.Schema {
+	t.Helper()
+	tables, err := mysql.ParseDdl(ddl)
+	if err != nil {
+		t.Fatalf("ParseDdl() error = %v", err)
+	}
+
+	return &schema.Schema{Version: schema.SchemaVersion, Tables: tables}
+}
+
+// liveColumn 按MySQL 8 information_schema.columns 的返回值构造字段
+func liveColumn(position int64, name string, columnType string, nullable string, def sql.NullString, extra string) schema.TableColumn {
+	column := schema.TableColumn{
+		OrdinalPosition: position,
+		ColumnName:      name,
+		ColumnType:      columnType,
+		DataType:        columnType,
+		ColumnKey:       sql.NullString{Valid: true},
+		IsNullable:      nullable,
+		ColumnComment:   sql.NullString{Valid: true},
+		ColumnDefault:   def,
+	}
+	column.SetExtra(extra)
+
+	return column
+}
+
+func TestCompareTimestampDefaultsMatchLiveMysql(t *testing.T) {
+	ddl := parseSchema(t, "CREATE TABLE `log` (\n"+
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n"+
+		"  `created_at` datetime DEFAULT CURRENT_TIMESTAMP,\n"+
+		"  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),\n"+
+		"  `seen_at` timestamp NULL DEFAULT now() ON UPDATE now(),\n"+
+		"  `uuid` varchar(36) DEFAULT (uuid()),\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		");")
+
+	// information_schema 中表达式默认值的 EXTRA 为 DEFAULT_GENERATED
+	id := liveColumn(1, "id", "bigint", "NO", sql.NullString{}, "auto_increment")
+	id.ColumnKey.String = "PRI"
+	live := &schema.Schema{Version: schema.SchemaVersion, Tables: []schema.Table{{
+		Name: "log",
+		Columns: []schema.TableColumn{
+			id,
+			liveColumn(2, "created_at", "datetime", "YES", sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, "DEFAULT_GENERATED"),
+			liveColumn(3, "updated_at", "datetime(3)", "NO", sql.NullString{String: "CURRENT_TIMESTAMP(3)", Valid: true}, "DEFAULT_GENERATED on update CURRENT_TIMESTAMP(3)"),
+			liveColumn(4, "seen_at", "timestamp", "YES", sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"),
+			liveColumn(5, "uuid", "varchar(36)", "YES", sql.NullString{String: "uuid()", Valid: true}, "DEFAULT_GENERATED"),
+		},
+		Indexes: []schema.TableIndex{{Name: schema.PrimaryKey, Unique: true, Type: "BTREE", Columns: []schema.IndexColumn{{Name: "id"}}}},
+	}}}
+
+	for _, pair := range [][2]*schema.Schema{{ddl, live}, {live, ddl}} {
+		if result := Compare(pair[0], pair[1]); !result.Empty() {
+			t.Errorf("Compare() = %s, want no changes", result.Text())
+		}
+	}
+}
+
+func TestCompareColumns(t *testing.T) {
+	base := "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称', `created_at` datetime DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`id`));"
+	tests := []struct {
+		name   string
+		target string
+		want   []Change
+	}{
+		{
+			name:   "type",
+			target: "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(64) NOT NULL DEFAULT '' COMMENT '名称', `created_at` datetime DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`id`));",
+			want:   []Change{{Object: ObjectColumn, Name: "name", Action: ActionChanged, Attribute: "type", Old: "varchar(32)", New: "varchar(64)"}},
+		},
+		{
+			name:   "nullable and comment",
+			target: "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(32) DEFAULT '' COMMENT '用户名', `created_at` datetime DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`id`));",
+			want: []Change{
+				{Object: ObjectColumn, Name: "name", Action: ActionChanged, Attribute: "nullable", Old: "NO", New: "YES"},
+				{Object: ObjectColumn, Name: "name", Action: ActionChanged, Attribute: "comment", Old: "名称", New: "用户名"},
+			},
+		},
+		{
+			name:   "literal default becomes expression",
+			target: "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称', `created_at` datetime DEFAULT 'CURRENT_TIMESTAMP', PRIMARY KEY (`id`));",
+			want:   []Change{{Object: ObjectColumn, Name: "created_at", Action: ActionChanged, Attribute: "default", Old: "CURRENT_TIMESTAMP", New: "'CURRENT_TIMESTAMP'"}},
+		},
+		{
+			name:   "on update",
+			target: "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称', `created_at` datetime DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, PRIMARY KEY (`id`));",
+			want:   []Change{{Object: ObjectColumn, Name: "created_at", Action: ActionChanged, Attribute: "extra", Old: "", New: "on update CURRENT_TIMESTAMP"}},
+		},
+		{
+			name:   "added and removed",
+			target: "CREATE TABLE `user` (`id` int NOT NULL, `name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称', `age` int unsigned NOT NULL DEFAULT '0', PRIMARY KEY (`id`));",
+			want: []Change{
+				{Object: ObjectColumn, Name: "age", Action: ActionAdded, Definition: "int unsigned NOT NULL DEFAULT '0'"},
+				{Object: ObjectColumn, Name: "created_at", Action: ActionRemoved, Definition: "datetime DEFAULT CURRENT_TIMESTAMP"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(parseSchema(t, base), parseSchema(t, tt.target))
+			if len(result.Tables) != 1 {
+				t.Fatalf("Compare() tables = %d, want 1", len(result.Tables))
+			}
+			if got := result.Tables[0].Changes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() changes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareTablesIndexesAndForeignKeys(t *testing.T) {
+	source := parseSchema(t, `
+CREATE TABLE user (id int NOT NULL, email varchar(64) NOT NULL, PRIMARY KEY (id), KEY idx_email (email));
+CREATE TABLE post (id int NOT NULL, user_id int NOT NULL, PRIMARY KEY (id));
+CREATE TABLE legacy (id int NOT NULL);
+`)
+	target := parseSchema(t, `
+CREATE TABLE user (id int NOT NULL, email varchar(64) NOT NULL, PRIMARY KEY (id), UNIQUE KEY idx_email (email(32)));
+CREATE TABLE post (id int NOT NULL, user_id int NOT NULL, PRIMARY KEY (id), CONSTRAINT fk_post_user FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE);
+CREATE TABLE tag (id int NOT NULL);
+`)
+
+	result := Compare(source, target)
+	got := make(map[string]string)
+	for _, table := range result.Tables {
+		got[table.Name] = table.Action
+	}
+	want := map[string]string{"legacy": ActionRemoved, "post": ActionChanged, "tag": ActionAdded, "user": ActionChanged}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Compare() tables = %v, want %v", got, want)
+	}
+
+	wantChanges := map[string][]Change{
+		"post": {
+			{Object: ObjectIndex, Name: "fk_post_user", Action: ActionAdded, Definition: "BTREE (user_id)"},
+			{Object: ObjectForeignKey, Name: "fk_post_user", Action: ActionAdded, Definition: "(user_id) REFERENCES user (id) ON UPDATE NO ACTION ON DELETE CASCADE"},
+		},
+		"user": {
+			{Object: ObjectIndex, Name: "idx_email", Action: ActionChanged, Attribute: "definition", Old: "BTREE (email)", New: "UNIQUE BTREE (email(32))"},
+		},
+	}
+	for _, table := range result.Tables {
+		want, ok := wantChanges[table.Name]
+		if !ok {
+			continue
+		}
+		if !reflect.DeepEqual(table.Changes, want) {
+			t.Errorf("Compare() %s changes = %+v, want %+v", table.Name, table.Changes, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package diff
+// @Description: 差异输出，支持文本、markdown及json
+// @Auth shigx 2024-07-22 15:06:41
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 输出格式
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// actionSymbols 文本格式中差异类型的标记
+var actionSymbols = map[string]string{
+	ActionAdded:   "+",
+	ActionRemoved: "-",
+	ActionChanged: "~",
+}
+
+// actionNames markdown格式中差异类型的名称
+var actionNames = map[string]string{
+	ActionAdded:   "新增",
+	ActionRemoved: "删除",
+	ActionChanged: "修改",
+}
+
+// objectNames markdown格式中差异对象的名称
+var objectNames = map[string]string{
+	ObjectTable:      "表",
+	ObjectColumn:     "字段",
+	ObjectIndex:      "索引",
+	ObjectForeignKey: "外键",
+}
+
+// Format
+//
+//	@Description: 按格式输出差异
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param format text、markdown、json
+//	@return string
+//	@return error
+func (r *Result) Format(format string) (string, error) {
+	switch format {
+	case FormatText, "":
+		return r.Text(), nil
+	case FormatMarkdown, "md":
+		return r.Markdown(), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+
+	return "", fmt.Errorf("不支持的输出格式：%s，可选值：%s、%s、%s", format, FormatText, FormatMarkdown, FormatJSON)
+}
+
+// Text 文本格式，+ 新增、- 删除、~ 修改
+func (r *Result) Text() string {
+	content := fmt.Sprintf("--- %s\n+++ %s\n", r.Source, r.Target)
+	if r.Empty() {
+		return content + "表结构一致\n"
+	}
+	for _, table := range r.Tables {
+		content += fmt.Sprintf("%s table %s\n", actionSymbols[table.Action], table.Name)
+		for _, change := range table.Changes {
+			content += fmt.Sprintf("    %s %s %s", actionSymbols[change.Action], change.Object, change.Name)
+			if change.Action == ActionChanged {
+				content += fmt.Sprintf(" %s: %s -> %s\n", change.Attribute, textValue(change.Old), textValue(change.New))
+				continue
+			}
+			content += " " + change.Definition + "\n"
+		}
+	}
+	content += "\n" + r.summary() + "\n"
+
+	return content
+}
+
+// Markdown markdown格式，新增、删除的表列出表名，修改的表列出变更明细
+func (r *Result) Markdown() string {
+	content := fmt.Sprintf("#### 表结构差异\n\n- 原表结构：%s\n- 目标表结构：%s\n\n", mdCell(r.Source), mdCell(r.Target))
+	if r.Empty() {
+		return content + "表结构一致\n"
+	}
+	content += r.summary() + "\n\n" +
+		"| 表名 | 变更 |\n" +
+		"| :--- | :---: |\n"
+	for _, table := range r.Tables {
+		content += fmt.Sprintf("| %s | %s |\n", table.Name, actionNames[table.Action])
+	}
+	for _, table := range r.Tables {
+		if table.Action != ActionChanged {
+			continue
+		}
+		content += fmt.Sprintf("\n##### %s\n\n", table.Name) +
+			"| 对象 | 名称 | 变更 | 属性 | 原值 | 新值 |\n" +
+			"| :---: | :--- | :---: | :---: | :--- | :--- |\n"
+		for _, change := range table.Changes {
+			old, cur := change.Old, change.New
+			switch change.Action {
+			case ActionAdded:
+				cur = change.Definition
+			case ActionRemoved:
+				old = change.Definition
+			}
+			content += fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+				objectNames[change.Object],
+				change.Name,
+				actionNames[change.Action],
+				change.Attribute,
+				mdCell(old),
+				mdCell(cur),
+			)
+		}
+	}
+
+	return content
+}
+
+// summary 差异汇总
+func (r *Result) summary() string {
+	count := map[string]int{}
+	for _, table := range r.Tables {
+		count[table.Action]++
+	}
+
+	return fmt.Sprintf("新增 %d 个表，删除 %d 个表，修改 %d 个表", count[ActionAdded], count[ActionRemoved], count[ActionChanged])
+}
+
+// textValue 空值显示为 ""
+func textValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	return s
+}
+
+// mdCell 转义表格单元格中的竖线并去除换行
+func mdCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
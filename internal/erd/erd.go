@@ -0,0 +1,277 @@
+// Package erd
+// @Description: 表结构生成ER图，支持 Mermaid erDiagram 及 PlantUML
+// @Auth shigx 2024-07-08 14:36:20
+package erd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 输出格式
+const (
+	FormatMermaid  = "mermaid"
+	FormatPlantUML = "plantuml"
+)
+
+// invalidName 实体名中不允许的字符
+var invalidName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Generate
+//
+//	@Description: 按格式生成ER图，只输出两端都在 tables 中的外键关系
+//	@Auth shigx 2024-07-08 14:36:20
+//	@param format mermaid、plantuml
+//	@param tables
+//	@return string
+//	@return error
+func Generate(format string, tables []*schema.Table) (string, error) {
+	switch format {
+	case FormatMermaid:
+		return Mermaid(tables), nil
+	case FormatPlantUML:
+		return PlantUML(tables), nil
+	}
+
+	return "", fmt.Errorf("不支持的ER图格式：%s，可选值：%s、%s", format, FormatMermaid, FormatPlantUML)
+}
+
+// Markdown
+//
+//	@Description: 生成可嵌入markdown文档的ER图代码块
+//	@Auth shigx 2024-07-08 14:36:20
+//	@param format mermaid、plantuml
+//	@param tables
+//	@return string
+//	@return error
+func Markdown(format string, tables []*schema.Table) (string, error) {
+	content, err := Generate(format, tables)
+	if err != nil {
+		return "", err
+	}
+
+	return "```" + format + "\n" + content + "```\n", nil
+}
+
+// Mermaid
+//
+//	@Description: 生成 Mermaid erDiagram，字段类型使用数据类型，键标记为 PK、FK、UK
+//	@Auth shigx 2024-07-08 14:36:20
+//	@param tables
+//	@return string
+func Mermaid(tables []*schema.Table) string {
+	content := "erDiagram\n"
+	for _, table := range tables {
+		content += fmt.Sprintf("    %s {\n", entityName(table.Name))
+		for _, column := range table.Columns {
+			content += fmt.Sprintf("        %s %s", entityName(column.DataType), entityName(column.ColumnName))
+			if keys := columnKeys(table, column.ColumnName); len(keys) > 0 {
+				content += " " + strings.Join(keys, ",")
+			}
+			if comment := label(column.ColumnComment.String); comment != "" {
+				content += fmt.Sprintf(` "%s"`, comment)
+			}
+			content += "\n"
+		}
+		content += "    }\n"
+	}
+	for _, rel := range relations(tables) {
+		content += fmt.Sprintf("    %s %s %s : \"%s\"\n",
+			entityName(rel.fk.ReferencedTable),
+			rel.cardinality(),
+			entityName(rel.fk.Table),
+			label(rel.fk.Name),
+		)
+	}
+
+	return content
+}
+
+// PlantUML
+//
+//	@Description: 生成 PlantUML 实体关系图，主键字段在分隔线上方，非空字段以 * 标记
+//	@Auth shigx 2024-07-08 14:36:20
+//	@param tables
+//	@return string
+func PlantUML(tables []*schema.Table) string {
+	content := "@startuml\nhide circle\nskinparam linetype ortho\n\n"
+	for _, table := range tables {
+		title := table.Name
+		if comment := label(table.Comment); comment != "" {
+			title += "\\n" + comment
+		}
+		content += fmt.Sprintf("entity \"%s\" as %s {\n", title, entityName(table.Name))
+		primary, others := splitPrimary(table)
+		for _, column := range primary {
+			content += "  " + plantUMLColumn(table, column)
+		}
+		content += "  --\n"
+		for _, column := range others {
+			content += "  " + plantUMLColumn(table, column)
+		}
+		content += "}\n\n"
+	}
+	for _, rel := range relations(tables) {
+		content += fmt.Sprintf("%s %s %s : %s\n",
+			entityName(rel.fk.ReferencedTable),
+			rel.cardinality(),
+			entityName(rel.fk.Table),
+			label(rel.fk.Name),
+		)
+	}
+	content += "@enduml\n"
+
+	return content
+}
+
+// plantUMLColumn 生成 PlantUML 字段行，例：* user_id : bigint unsigned <<FK>>
+func plantUMLColumn(table *schema.Table, column schema.TableColumn) string {
+	line := ""
+	if column.IsNullable == "NO" {
+		line += "* "
+	}
+	line += fmt.Sprintf("%s : %s", column.ColumnName, column.ColumnType)
+	for _, key := range columnKeys(table, column.ColumnName) {
+		line += " <<" + key + ">>"
+	}
+
+	return line + "\n"
+}
+
+// splitPrimary 按是否主键字段拆分字段列表
+func splitPrimary(table *schema.Table) (primary []schema.TableColumn, others []schema.TableColumn) {
+	for _, column := range table.Columns {
+		if hasKey(table, column.ColumnName, "PK") {
+			primary = append(primary, column)
+			continue
+		}
+		others = append(others, column)
+	}
+
+	return primary, others
+}
+
+// columnKeys 返回字段的键标记，主键 PK、外键 FK、唯一索引 UK
+func columnKeys(table *schema.Table, column string) []string {
+	keys := make([]string, 0)
+	for _, key := range []string{"PK", "FK", "UK"} {
+		if hasKey(table, column, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// hasKey 判断字段是否属于指定类型的键
+func hasKey(table *schema.Table, column string, key string) bool {
+	switch key {
+	case "PK", "UK":
+		for _, index := range table.Indexes {
+			if index.IsPrimary() != (key == "PK") || !index.Unique {
+				continue
+			}
+			if contains(index.ColumnNames(), column) {
+				return true
+			}
+		}
+	case "FK":
+		for _, fk := range table.ForeignKeys {
+			if contains(fk.Columns, column) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// relation 表间外键关系
+type relation struct {
+	fk       schema.ForeignKey
+	nullable bool // 外键字段可为空，引用的记录可以不存在
+	unique   bool // 外键字段唯一，一对一关系
+}
+
+// cardinality 返回关系连线，Mermaid 与 PlantUML 使用相同的写法
+func (r relation) cardinality() string {
+	parent := "||"
+	if r.nullable {
+		parent = "|o"
+	}
+	child := "o{"
+	if r.unique {
+		child = "o|"
+	}
+
+	return parent + "--" + child
+}
+
+// relations 返回两端都在 tables 中的外键关系，按表顺序及外键顺序输出
+func relations(tables []*schema.Table) []relation {
+	selected := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		selected[table.Name] = true
+	}
+
+	ret := make([]relation, 0)
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if !selected[fk.ReferencedTable] {
+				continue
+			}
+			rel := relation{fk: fk}
+			for _, column := range table.Columns {
+				if contains(fk.Columns, column.ColumnName) && column.IsNullable == "YES" {
+					rel.nullable = true
+				}
+			}
+			for _, index := range table.Indexes {
+				if index.Unique && sameColumns(index.ColumnNames(), fk.Columns) {
+					rel.unique = true
+				}
+			}
+			ret = append(ret, rel)
+		}
+	}
+
+	return ret
+}
+
+// entityName 转换为图中可用的名称，非字母数字下划线替换为下划线
+func entityName(s string) string {
+	return invalidName.ReplaceAllString(s, "_")
+}
+
+// label 转换为图中可用的文本，\r\n、\n 等换行及连续空白合并为一个空格，双引号替换为单引号
+func label(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.ReplaceAll(s, `"`, "'")
+}
+
+// contains 判断列表中是否包含指定值
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameColumns 判断两个字段列表是否包含相同的字段，不区分顺序
+func sameColumns(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, item := range a {
+		if !contains(b, item) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package erd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// update 重新生成 testdata 下的 .golden 文件：go test ./internal/erd -update
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden 对比输出与 testdata 下的golden文件
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+// readTables 读取 testdata/shop.sql，按建表顺序返回，tenant 表不在其中
+func readTables(t *testing.T) []*schema.Table {
+	t.Helper()
+	tables, err := mysql.ReadDdl(filepath.Join("testdata", "shop.sql"))
+	if err != nil {
+		t.Fatalf("ReadDdl() error = %v", err)
+	}
+	ret := make([]*schema.Table, 0, len(tables))
+	for k := range tables {
+		ret = append(ret, &tables[k])
+	}
+	// 多行备注及双引号
+	ret[0].Comment = "用户\r\n\"注册\"账号"
+	ret[0].Columns[1].ColumnComment.String = "邮箱\n登录名 \"唯一\""
+
+	return ret
+}
+
+func TestMermaid(t *testing.T) {
+	assertGolden(t, "shop.mermaid.golden", Mermaid(readTables(t)))
+}
+
+func TestPlantUML(t *testing.T) {
+	assertGolden(t, "shop.plantuml.golden", PlantUML(readTables(t)))
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"用户", "用户"},
+		{"a\r\nb", "a b"},
+		{"a\nb\rc", "a b c"},
+		{" a \r\n\r\n b ", "a b"},
+		{`say "hi"`, "say 'hi'"},
+	}
+	for _, tt := range tests {
+		if got := label(tt.in); got != tt.want {
+			t.Errorf("label(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateFormat(t *testing.T) {
+	if _, err := Generate("dot", readTables(t)); err == nil {
+		t.Error("Generate(dot) error = nil, want unsupported format")
+	}
+}
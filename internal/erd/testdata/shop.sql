@@ -0,0 +1,25 @@
+-- 覆盖一对多、可为空外键、唯一外键（一对一）、未选择的关联表及需要替换字符的表名、字段名
+CREATE TABLE `user` (
+  `id` bigint NOT NULL,
+  `email` varchar(128) NOT NULL COMMENT '邮箱',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_email` (`email`)
+) COMMENT='用户';
+
+CREATE TABLE `user_profile` (
+  `user_id` bigint NOT NULL,
+  `bio` text,
+  PRIMARY KEY (`user_id`),
+  CONSTRAINT `fk_profile_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)
+);
+
+CREATE TABLE `order-item` (
+  `id` bigint NOT NULL,
+  `user_id` bigint NOT NULL,
+  `coupon user` bigint DEFAULT NULL,
+  `tenant_id` bigint NOT NULL,
+  PRIMARY KEY (`id`),
+  CONSTRAINT `fk_item_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`),
+  CONSTRAINT `fk_item_coupon_user` FOREIGN KEY (`coupon user`) REFERENCES `user` (`id`),
+  CONSTRAINT `fk_item_tenant` FOREIGN KEY (`tenant_id`) REFERENCES `tenant` (`id`)
+);
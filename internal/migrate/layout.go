@@ -0,0 +1,87 @@
+// Package migrate
+// @Description: 迁移文件布局，支持 golang-migrate 及 goose
+// @Auth shigx 2024-07-29 11:20:37
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 迁移文件布局
+const (
+	LayoutGolangMigrate = "golang-migrate"
+	LayoutGoose         = "goose"
+)
+
+// invalidFileName 迁移名称中不允许的字符
+var invalidFileName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// File @Description 迁移文件
+// @Auth shigx
+// @Date 2024-07-29 11:20:37
+type File struct {
+	Name    string // 文件名
+	Content string // 文件内容
+}
+
+// Files
+//
+//	@Description: 按布局生成迁移文件，golang-migrate 为 <version>_<name>.up.sql、.down.sql，goose 为 <version>_<name>.sql
+//	@Auth shigx 2024-07-29 11:20:37
+//	@param layout golang-migrate、goose
+//	@param version 版本号，例：20240729112037
+//	@param name 迁移名称
+//	@param up 升级语句
+//	@param down 回滚语句，为nil时不生成回滚
+//	@return []File
+//	@return error
+func Files(layout string, version string, name string, up []Statement, down []Statement) ([]File, error) {
+	prefix := version + "_" + strings.Trim(invalidFileName.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	switch layout {
+	case LayoutGolangMigrate:
+		files := []File{{Name: prefix + ".up.sql", Content: Render(up)}}
+		if down != nil {
+			files = append(files, File{Name: prefix + ".down.sql", Content: Render(down)})
+		}
+		return files, nil
+	case LayoutGoose:
+		content := "-- +goose Up\n" + Render(up)
+		if down != nil {
+			content += "\n-- +goose Down\n" + Render(down)
+		}
+		return []File{{Name: prefix + ".sql", Content: content}}, nil
+	}
+
+	return nil, fmt.Errorf("不支持的迁移文件布局：%s，可选值：%s、%s", layout, LayoutGolangMigrate, LayoutGoose)
+}
+
+// Render 输出迁移语句，每条语句以空行分隔，需人工确认的说明输出为注释
+func Render(statements []Statement) string {
+	blocks := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		block := statement.SQL
+		if statement.Warning != "" {
+			block = "-- 警告：" + statement.Warning + "\n" + block
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// Destructive 返回可能丢失数据的语句
+func Destructive(statements []Statement) []Statement {
+	ret := make([]Statement, 0)
+	for _, statement := range statements {
+		if statement.Destructive {
+			ret = append(ret, statement)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,365 @@
+// Package migrate
+// @Description: 按表结构差异生成迁移sql
+// @Auth shigx 2024-07-29 11:20:37
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"tool-cli/internal/diff"
+	"tool-cli/internal/schema"
+)
+
+// Statement @Description 迁移语句
+// @Auth shigx
+// @Date 2024-07-29 11:20:37
+type Statement struct {
+	SQL         string // 以分号结尾的sql
+	Destructive bool   // 是否可能丢失数据或失败：删除表、删除字段、修改字段类型、允许为空改为非空、删除主键
+	Warning     string // 需要人工确认的说明，输出为sql注释
+}
+
+// numericTypes 默认值不加引号的数值类型
+var numericTypes = map[string]bool{
+	"tinyint": true, "smallint": true, "mediumint": true, "int": true, "integer": true, "bigint": true,
+	"float": true, "double": true, "real": true, "decimal": true, "numeric": true, "bit": true,
+}
+
+// temporalTypes 日期时间类型，CURRENT_TIMESTAMP、NOW() 等默认值不加括号
+var temporalTypes = map[string]bool{
+	"date": true, "time": true, "datetime": true, "timestamp": true, "year": true,
+}
+
+// Generate
+//
+//	@Description: 生成将 current 变更为 desired 的迁移语句，顺序为：删除外键、创建表、修改表、删除表、添加外键
+//	@Auth shigx 2024-07-29 11:20:37
+//	@param current 当前表结构
+//	@param desired 期望表结构
+//	@return []Statement
+func Generate(current *schema.Schema, desired *schema.Schema) []Statement {
+	result := diff.Compare(current, desired)
+	var (
+		dropKeys   = make([]Statement, 0)
+		creates    = make([]Statement, 0)
+		alters     = make([]Statement, 0)
+		drops      = make([]Statement, 0)
+		addKeys    = make([]Statement, 0)
+		changedFks = make(map[string]bool)
+	)
+	for _, table := range result.Tables {
+		switch table.Action {
+		case diff.ActionAdded:
+			creates = append(creates, createStatement(table.Table))
+			for _, fk := range table.Table.ForeignKeys {
+				addKeys = append(addKeys, Statement{SQL: fmt.Sprintf("ALTER TABLE %s ADD %s;", quoteName(table.Name), foreignKeyDefinition(fk))})
+			}
+		case diff.ActionRemoved:
+			for _, fk := range table.Table.ForeignKeys {
+				dropKeys = append(dropKeys, Statement{SQL: fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", quoteName(table.Name), quoteName(fk.Name))})
+			}
+			drops = append(drops, Statement{SQL: fmt.Sprintf("DROP TABLE %s;", quoteName(table.Name)), Destructive: true})
+		case diff.ActionChanged:
+			old, cur := current.Table(table.Name), desired.Table(table.Name)
+			for _, change := range table.Changes {
+				if change.Object != diff.ObjectForeignKey || changedFks[table.Name+"."+change.Name] {
+					continue
+				}
+				changedFks[table.Name+"."+change.Name] = true
+				if change.Action != diff.ActionAdded {
+					dropKeys = append(dropKeys, Statement{SQL: fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s;", quoteName(table.Name), quoteName(change.Name))})
+				}
+				if fk := findForeignKey(cur.ForeignKeys, change.Name); fk != nil {
+					addKeys = append(addKeys, Statement{SQL: fmt.Sprintf("ALTER TABLE %s ADD %s;", quoteName(table.Name), foreignKeyDefinition(*fk))})
+				}
+			}
+			alters = append(alters, alterTable(old, cur, table.Changes)...)
+		}
+	}
+
+	ret := make([]Statement, 0)
+	for _, group := range [][]Statement{dropKeys, creates, alters, drops, addKeys} {
+		ret = append(ret, group...)
+	}
+
+	return ret
+}
+
+// alterTable 生成修改表的语句，顺序为：删除索引、修改表备注、新增字段、修改字段、删除字段、添加索引
+func alterTable(old *schema.Table, cur *schema.Table, changes []diff.Change) []Statement {
+	var (
+		name         = quoteName(cur.Name)
+		dropIndexes  = make([]Statement, 0)
+		tableChanges = make([]Statement, 0)
+		addColumns   = make([]Statement, 0)
+		modifies     = make([]Statement, 0)
+		dropColumns  = make([]Statement, 0)
+		addIndexes   = make([]Statement, 0)
+		seen         = make(map[string]bool)
+	)
+	for _, change := range changes {
+		key := change.Object + "." + change.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		switch change.Object {
+		case diff.ObjectTable:
+			tableChanges = append(tableChanges, Statement{SQL: fmt.Sprintf("ALTER TABLE %s COMMENT %s;", name, quote(cur.Comment))})
+		case diff.ObjectColumn:
+			switch change.Action {
+			case diff.ActionAdded:
+				column, position := findColumn(cur.Columns, change.Name)
+				addColumns = append(addColumns, columnStatement(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s%s;", name, ColumnDefinition(*column), columnPosition(cur.Columns, position)), *column))
+			case diff.ActionRemoved:
+				dropColumns = append(dropColumns, Statement{SQL: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", name, quoteName(change.Name)), Destructive: true})
+			case diff.ActionChanged:
+				column, _ := findColumn(cur.Columns, change.Name)
+				oldColumn, _ := findColumn(old.Columns, change.Name)
+				statement := columnStatement(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;", name, ColumnDefinition(*column)), *column)
+				// 修改类型可能截断数据，改为非空时已有的null值会导致失败
+				statement.Destructive = oldColumn.ColumnType != column.ColumnType || (oldColumn.IsNullable == "YES" && column.IsNullable == "NO")
+				modifies = append(modifies, statement)
+			}
+		case diff.ObjectIndex:
+			if change.Action != diff.ActionAdded {
+				// 删除主键会失去唯一约束，自增字段的主键无法直接删除
+				dropIndexes = append(dropIndexes, Statement{SQL: fmt.Sprintf("ALTER TABLE %s DROP %s;", name, dropIndexClause(change.Name)), Destructive: change.Name == schema.PrimaryKey})
+			}
+			if index := findIndex(cur.Indexes, change.Name); index != nil {
+				addIndexes = append(addIndexes, Statement{SQL: fmt.Sprintf("ALTER TABLE %s ADD %s;", name, IndexDefinition(*index))})
+			}
+		}
+	}
+
+	ret := make([]Statement, 0)
+	for _, group := range [][]Statement{dropIndexes, tableChanges, addColumns, modifies, dropColumns, addIndexes} {
+		ret = append(ret, group...)
+	}
+
+	return ret
+}
+
+// CreateTable
+//
+//	@Description: 生成建表语句，外键单独添加，不包含在建表语句中
+//	@Auth shigx 2024-07-29 11:20:37
+//	@param table
+//	@return string
+func CreateTable(table *schema.Table) string {
+	lines := make([]string, 0, len(table.Columns)+len(table.Indexes))
+	for _, column := range table.Columns {
+		lines = append(lines, "  "+ColumnDefinition(column))
+	}
+	for _, index := range table.Indexes {
+		lines = append(lines, "  "+IndexDefinition(index))
+	}
+
+	content := fmt.Sprintf("CREATE TABLE %s (\n%s\n)", quoteName(table.Name), strings.Join(lines, ",\n"))
+	if table.Comment != "" {
+		content += " COMMENT=" + quote(table.Comment)
+	}
+
+	return content + ";"
+}
+
+// ColumnDefinition 返回字段定义sql，例：`name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称'
+func ColumnDefinition(column schema.TableColumn) string {
+	parts := []string{quoteName(column.ColumnName), column.ColumnType}
+	if column.IsNullable == "NO" {
+		parts = append(parts, "NOT NULL")
+	} else {
+		parts = append(parts, "NULL")
+	}
+	if value, ok := defaultValue(column); ok {
+		parts = append(parts, "DEFAULT "+value)
+	}
+	if column.AutoIncrement {
+		parts = append(parts, "AUTO_INCREMENT")
+	}
+	if column.OnUpdate != "" {
+		parts = append(parts, "ON UPDATE "+strings.ToUpper(column.OnUpdate))
+	}
+	if column.ColumnComment.String != "" {
+		parts = append(parts, "COMMENT "+quote(column.ColumnComment.String))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// IndexDefinition 返回索引定义sql，例：UNIQUE KEY `uk_name` (`name`(10))、KEY `idx_lower_name` ((lower(`name`)))
+func IndexDefinition(index schema.TableIndex) string {
+	columns := make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		if column.Expression != "" {
+			columns = append(columns, "("+column.Expression+")")
+			continue
+		}
+		if column.Length > 0 {
+			columns = append(columns, fmt.Sprintf("%s(%d)", quoteName(column.Name), column.Length))
+			continue
+		}
+		columns = append(columns, quoteName(column.Name))
+	}
+
+	var content string
+	switch {
+	case index.IsPrimary():
+		content = "PRIMARY KEY"
+	case index.Type == "FULLTEXT", index.Type == "SPATIAL":
+		content = index.Type + " KEY " + quoteName(index.Name)
+	case index.Unique:
+		content = "UNIQUE KEY " + quoteName(index.Name)
+	default:
+		content = "KEY " + quoteName(index.Name)
+	}
+	content += " (" + strings.Join(columns, ", ") + ")"
+	if index.Type == "HASH" {
+		content += " USING HASH"
+	}
+	if index.Comment != "" {
+		content += " COMMENT " + quote(index.Comment)
+	}
+
+	return content
+}
+
+// foreignKeyDefinition 返回外键定义sql
+func foreignKeyDefinition(fk schema.ForeignKey) string {
+	content := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		quoteName(fk.Name),
+		quoteNames(fk.Columns),
+		quoteName(fk.ReferencedTable),
+		quoteNames(fk.ReferencedColumns),
+	)
+	if fk.OnUpdate != "" && fk.OnUpdate != "NO ACTION" {
+		content += " ON UPDATE " + fk.OnUpdate
+	}
+	if fk.OnDelete != "" && fk.OnDelete != "NO ACTION" {
+		content += " ON DELETE " + fk.OnDelete
+	}
+
+	return content
+}
+
+// createStatement 生成建表语句，存在生成列时输出警告
+func createStatement(table *schema.Table) Statement {
+	statement := Statement{SQL: CreateTable(table)}
+	generated := make([]string, 0)
+	for _, column := range table.Columns {
+		if column.Generated != "" {
+			generated = append(generated, column.ColumnName)
+		}
+	}
+	if len(generated) > 0 {
+		statement.Warning = fmt.Sprintf("字段 %s 为生成列，需手动补充 GENERATED ALWAYS AS 表达式", strings.Join(generated, "、"))
+	}
+
+	return statement
+}
+
+// columnStatement 生成字段语句，生成列的表达式未读取，需人工补充
+func columnStatement(sql string, column schema.TableColumn) Statement {
+	statement := Statement{SQL: sql}
+	if column.Generated != "" {
+		statement.Warning = fmt.Sprintf("字段 %s 为生成列，需手动补充 GENERATED ALWAYS AS 表达式", column.ColumnName)
+	}
+
+	return statement
+}
+
+// columnPosition 返回新增字段的位置子句
+func columnPosition(columns []schema.TableColumn, position int) string {
+	if position == 0 {
+		return " FIRST"
+	}
+
+	return " AFTER " + quoteName(columns[position-1].ColumnName)
+}
+
+// dropIndexClause 返回删除索引子句，主键为 DROP PRIMARY KEY
+func dropIndexClause(name string) string {
+	if name == schema.PrimaryKey {
+		return "PRIMARY KEY"
+	}
+
+	return "INDEX " + quoteName(name)
+}
+
+// defaultValue 返回默认值sql，字符串加引号，日期时间类型的 CURRENT_TIMESTAMP 等函数、数值不加引号，表达式默认值加括号
+func defaultValue(column schema.TableColumn) (string, bool) {
+	if !column.ColumnDefault.Valid {
+		return "", false
+	}
+
+	value := column.ColumnDefault.String
+	upper := strings.ToUpper(value)
+	switch {
+	case temporalTypes[strings.ToLower(column.DataType)] && (strings.HasPrefix(upper, "CURRENT_TIMESTAMP") ||
+		strings.HasPrefix(upper, "CURRENT_DATE") || strings.HasPrefix(upper, "CURRENT_TIME") ||
+		strings.HasPrefix(upper, "LOCALTIME") || strings.HasPrefix(upper, "NOW(")):
+		return value, true
+	case column.DefaultExpr:
+		return "(" + value + ")", true
+	case strings.HasPrefix(value, "b'"), strings.HasPrefix(value, "x'"):
+		return value, true
+	case numericTypes[column.DataType] && value != "":
+		return value, true
+	}
+
+	return quote(value), true
+}
+
+// findColumn 返回指定字段及其位置
+func findColumn(columns []schema.TableColumn, name string) (*schema.TableColumn, int) {
+	for k := range columns {
+		if columns[k].ColumnName == name {
+			return &columns[k], k
+		}
+	}
+
+	return nil, -1
+}
+
+// findIndex 返回指定索引，不存在时返回nil
+func findIndex(indexes []schema.TableIndex, name string) *schema.TableIndex {
+	for k := range indexes {
+		if indexes[k].Name == name {
+			return &indexes[k]
+		}
+	}
+
+	return nil
+}
+
+// findForeignKey 返回指定外键，不存在时返回nil
+func findForeignKey(foreignKeys []schema.ForeignKey, name string) *schema.ForeignKey {
+	for k := range foreignKeys {
+		if foreignKeys[k].Name == name {
+			return &foreignKeys[k]
+		}
+	}
+
+	return nil
+}
+
+// quoteName 使用反引号包裹名称
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteNames 使用反引号包裹名称列表，以逗号分隔
+func quoteNames(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteName(name))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// quote 使用单引号包裹字符串
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(s) + "'"
+}
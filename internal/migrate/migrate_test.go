@@ -0,0 +1,264 @@
+package migrate
+
+import (
+	"database/sql"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// update 重新生成 testdata 下的 .golden 文件：go test ./internal/migrate -update
+var update = flag.Bool("update", false, "update golden files")
+
+// readSchema 读取 testdata 下的建表语句
+func readSchema(t *testing.T, name string) *schema.Schema {
+	t.Helper()
+	tables, err := mysql.ReadDdl(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadDdl(%s) error = %v", name, err)
+	}
+
+	return &schema.Schema{Version: schema.SchemaVersion, Tables: tables}
+}
+
+// assertGolden 对比输出与 testdata 下的golden文件
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	up := Generate(readSchema(t, "current.sql"), readSchema(t, "desired.sql"))
+	assertGolden(t, "up.golden", Render(up))
+
+	down := Generate(readSchema(t, "desired.sql"), readSchema(t, "current.sql"))
+	assertGolden(t, "down.golden", Render(down))
+}
+
+func TestGenerateOrder(t *testing.T) {
+	up := Generate(readSchema(t, "current.sql"), readSchema(t, "desired.sql"))
+
+	// 删除外键、创建表、修改表、删除表、添加外键
+	groups := []string{"drop foreign key", "create table", "alter table", "drop table", "add foreign key"}
+	group := func(sql string) int {
+		switch {
+		case strings.Contains(sql, "DROP FOREIGN KEY"):
+			return 0
+		case strings.HasPrefix(sql, "CREATE TABLE"):
+			return 1
+		case strings.HasPrefix(sql, "DROP TABLE"):
+			return 3
+		case strings.Contains(sql, "ADD CONSTRAINT"):
+			return 4
+		}
+		return 2
+	}
+	last := 0
+	seen := make(map[int]bool)
+	for _, statement := range up {
+		k := group(statement.SQL)
+		if k < last {
+			t.Fatalf("%s statement %q after %s statements", groups[k], statement.SQL, groups[last])
+		}
+		last = k
+		seen[k] = true
+	}
+	if len(seen) != len(groups) {
+		t.Errorf("Generate() covers %d statement groups, want %d", len(seen), len(groups))
+	}
+}
+
+func TestDestructive(t *testing.T) {
+	up := Generate(readSchema(t, "current.sql"), readSchema(t, "desired.sql"))
+
+	got := make([]string, 0)
+	for _, statement := range Destructive(up) {
+		got = append(got, statement.SQL)
+	}
+	want := []string{
+		"ALTER TABLE `order` MODIFY COLUMN `amount` decimal(10,2) NOT NULL DEFAULT 0.00;",
+		"ALTER TABLE `user` MODIFY COLUMN `name` varchar(64) NOT NULL DEFAULT '' COMMENT '名称';",
+		"ALTER TABLE `user` DROP COLUMN `nickname`;",
+		"DROP TABLE `legacy_log`;",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Destructive() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDestructiveColumnChanges(t *testing.T) {
+	parse := func(ddl string) *schema.Schema {
+		tables, err := mysql.ParseDdl(ddl)
+		if err != nil {
+			t.Fatalf("ParseDdl() error = %v", err)
+		}
+		return &schema.Schema{Version: schema.SchemaVersion, Tables: tables}
+	}
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    []string
+	}{
+		{
+			"nullable to not null",
+			"CREATE TABLE t (id bigint NOT NULL, a int DEFAULT NULL, PRIMARY KEY (id));",
+			"CREATE TABLE t (id bigint NOT NULL, a int NOT NULL DEFAULT '0', PRIMARY KEY (id));",
+			[]string{"ALTER TABLE `t` MODIFY COLUMN `a` int NOT NULL DEFAULT 0;"},
+		},
+		{
+			"not null to nullable",
+			"CREATE TABLE t (id bigint NOT NULL, a int NOT NULL, PRIMARY KEY (id));",
+			"CREATE TABLE t (id bigint NOT NULL, a int DEFAULT NULL, PRIMARY KEY (id));",
+			[]string{},
+		},
+		{
+			"comment only",
+			"CREATE TABLE t (id bigint NOT NULL, a int DEFAULT NULL, PRIMARY KEY (id));",
+			"CREATE TABLE t (id bigint NOT NULL, a int DEFAULT NULL COMMENT 'a', PRIMARY KEY (id));",
+			[]string{},
+		},
+		{
+			"drop primary key",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL, PRIMARY KEY (id));",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL);",
+			[]string{"ALTER TABLE `t` DROP PRIMARY KEY;"},
+		},
+		{
+			"change primary key",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL, PRIMARY KEY (id));",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL, PRIMARY KEY (id, code));",
+			[]string{"ALTER TABLE `t` DROP PRIMARY KEY;"},
+		},
+		{
+			"drop secondary index",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL, PRIMARY KEY (id), KEY idx_code (code));",
+			"CREATE TABLE t (id bigint NOT NULL, code int NOT NULL, PRIMARY KEY (id));",
+			[]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, statement := range Destructive(Generate(parse(tt.current), parse(tt.desired))) {
+				got = append(got, statement.SQL)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Destructive() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		dataType string
+		value    string
+		expr     bool
+		want     string
+	}{
+		{"int", "0", false, "0"},
+		{"varchar", "it's", false, "'it''s'"},
+		{"datetime", "CURRENT_TIMESTAMP", false, "CURRENT_TIMESTAMP"},
+		{"datetime", "CURRENT_TIMESTAMP", true, "CURRENT_TIMESTAMP"},
+		{"timestamp", "now()", false, "now()"},
+		{"json", "json_array()", true, "(json_array())"},
+		{"bit", "b'1'", false, "b'1'"},
+		// 非日期时间类型的同名字符串常量
+		{"varchar", "CURRENT_TIMESTAMP", false, "'CURRENT_TIMESTAMP'"},
+		{"varchar", "NOW()", false, "'NOW()'"},
+	}
+	for _, tt := range tests {
+		column := schema.TableColumn{DataType: tt.dataType, ColumnDefault: sql.NullString{String: tt.value, Valid: true}, DefaultExpr: tt.expr}
+		if got, ok := defaultValue(column); !ok || got != tt.want {
+			t.Errorf("defaultValue(%s %q) = %q, %v, want %q", tt.dataType, tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestGenerateConverges(t *testing.T) {
+	desired := readSchema(t, "desired.sql")
+	if statements := Generate(desired, readSchema(t, "desired.sql")); len(statements) != 0 {
+		t.Errorf("Generate(desired, desired) = %s, want no statements", Render(statements))
+	}
+
+	// 线上MySQL 8 中 CURRENT_TIMESTAMP 默认值的 EXTRA 为 DEFAULT_GENERATED，不应重复生成 MODIFY COLUMN
+	live := readSchema(t, "desired.sql")
+	user := live.Table("user")
+	for k := range user.Columns {
+		column := &user.Columns[k]
+		switch column.ColumnName {
+		case "created_at":
+			column.SetExtra("DEFAULT_GENERATED")
+		case "updated_at":
+			column.SetExtra("DEFAULT_GENERATED on update CURRENT_TIMESTAMP")
+		}
+	}
+	if statements := Generate(live, desired); len(statements) != 0 {
+		t.Errorf("Generate(live, desired) = %s, want no statements", Render(statements))
+	}
+}
+
+func TestFiles(t *testing.T) {
+	up := Generate(readSchema(t, "current.sql"), readSchema(t, "desired.sql"))
+	down := Generate(readSchema(t, "desired.sql"), readSchema(t, "current.sql"))
+
+	tests := []struct {
+		layout string
+		golden string
+		names  []string // 不生成回滚时的文件名
+	}{
+		{LayoutGolangMigrate, "golang-migrate.golden", []string{"20240729112037_add_user_email.up.sql"}},
+		{LayoutGoose, "goose.golden", []string{"20240729112037_add_user_email.sql"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			files, err := Files(tt.layout, "20240729112037", "Add user-email!", up, down)
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+			var buf strings.Builder
+			for _, file := range files {
+				buf.WriteString("==> " + file.Name + " <==\n" + file.Content)
+			}
+			assertGolden(t, tt.golden, buf.String())
+
+			// 不生成回滚
+			files, err = Files(tt.layout, "20240729112037", "Add user-email!", up, nil)
+			if err != nil {
+				t.Fatalf("Files() error = %v", err)
+			}
+			names := make([]string, 0, len(files))
+			for _, file := range files {
+				names = append(names, file.Name)
+				if strings.Contains(file.Content, "goose Down") {
+					t.Errorf("Files() without down contains goose Down section")
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+				t.Errorf("Files() without down names = %v, want %v", names, tt.names)
+			}
+		})
+	}
+
+	if _, err := Files("flyway", "1", "init", up, nil); err == nil {
+		t.Error("Files(flyway) error = nil, want unsupported layout error")
+	}
+}
@@ -0,0 +1,870 @@
+// Package mysql
+// @Title 建表语句解析
+// @Description 解析CREATE TABLE语句生成表结构信息，无需连接数据库
+// @Author shigx 2024-06-03 10:12:30
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 词法单元类型
+const (
+	tokenIdent  = iota // 标识符、关键字、数字
+	tokenQuoted        // 反引号包裹的标识符
+	tokenString        // 单引号、双引号字符串
+	tokenSymbol        // 符号
+)
+
+// token 词法单元
+type token struct {
+	kind int
+	text string
+}
+
+// is 判断是否为指定关键字（不区分大小写，引号标识符不视为关键字）
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+// isSymbol 判断是否为指定符号
+func (t token) isSymbol(symbol string) bool {
+	return t.kind == tokenSymbol && t.text == symbol
+}
+
+// raw 还原为sql文本
+func (t token) raw() string {
+	switch t.kind {
+	case tokenQuoted:
+		return "`" + strings.ReplaceAll(t.text, "`", "``") + "`"
+	case tokenString:
+		return "'" + strings.ReplaceAll(t.text, "'", "''") + "'"
+	}
+	return t.text
+}
+
+// ReadDdl
+//
+//	@Description: 读取建表语句，path可以是文件、目录（读取目录下全部.sql文件）或 - 表示标准输入
+//	@Auth shigx 2024-06-03 10:12:30
+//	@param path
+//	@return []schema.Table
+//	@return error
+func ReadDdl(path string) ([]schema.Table, error) {
+	if path == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, errors.Wrap(err, "read stdin err")
+		}
+		return ParseDdl(string(content))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.sql")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	tables := make([]schema.Table, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		ret, err := ParseDdl(string(content))
+		if err != nil {
+			return nil, errors.WithMessage(err, file)
+		}
+		tables = append(tables, ret...)
+	}
+	linkReferences(tables)
+
+	return tables, nil
+}
+
+// ParseDdl
+//
+//	@Description: 解析sql文本中的CREATE TABLE语句，其他语句忽略
+//	@Auth shigx 2024-06-03 10:12:30
+//	@param content
+//	@return []schema.Table
+//	@return error
+func ParseDdl(content string) ([]schema.Table, error) {
+	tokens, err := tokenize(content)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]schema.Table, 0)
+	for _, stmt := range splitStatements(tokens) {
+		table, ok, err := parseCreateTable(stmt)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			tables = append(tables, table)
+		}
+	}
+	linkReferences(tables)
+
+	return tables, nil
+}
+
+// linkReferences 根据各表外键填充被引用信息
+func linkReferences(tables []schema.Table) {
+	for k := range tables {
+		tables[k].ReferencedBy = nil
+	}
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			for k := range tables {
+				if tables[k].Name == fk.ReferencedTable {
+					tables[k].ReferencedBy = append(tables[k].ReferencedBy, fk)
+				}
+			}
+		}
+	}
+}
+
+// tokenize 词法分析，忽略注释和空白
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || isDashComment(s[i:]):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+		case c == '`' || c == '\'' || c == '"':
+			text, n, err := readQuoted(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			kind := tokenString
+			if c == '`' {
+				kind = tokenQuoted
+			}
+			tokens = append(tokens, token{kind: kind, text: text})
+			i += n
+		case isWordChar(c):
+			j := i
+			for j < len(s) && isWordChar(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: s[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// isDashComment 是否 -- 注释，与mysql一致 -- 后需为空白、控制字符或文本结尾
+func isDashComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+
+	return len(s) == 2 || s[2] <= ' '
+}
+
+// readQuoted 读取引号包裹的内容，返回去除引号和转义后的文本及消耗的字节数
+func readQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var buf strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && quote != '`' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'r':
+				buf.WriteByte('\r')
+			case '0':
+				buf.WriteByte(0)
+			default:
+				buf.WriteByte(s[i])
+			}
+		case c == quote && i+1 < len(s) && s[i+1] == quote:
+			buf.WriteByte(c)
+			i++
+		case c == quote:
+			return buf.String(), i + 1, nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.Errorf("unterminated quoted string: %.20s", s)
+}
+
+// isWordChar 标识符字符，包含非ASCII字符
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// splitStatements 按分号拆分语句
+func splitStatements(tokens []token) [][]token {
+	stmts := make([][]token, 0)
+	start := 0
+	for i, t := range tokens {
+		if t.isSymbol(";") {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		stmts = append(stmts, tokens[start:])
+	}
+
+	return stmts
+}
+
+// splitTopLevel 按最外层逗号拆分
+func splitTopLevel(tokens []token) [][]token {
+	parts := make([][]token, 0)
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.isSymbol("("):
+			depth++
+		case t.isSymbol(")"):
+			depth--
+		case t.isSymbol(",") && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		parts = append(parts, tokens[start:])
+	}
+
+	return parts
+}
+
+// closeParen 返回与tokens[start]左括号匹配的右括号下标
+func closeParen(tokens []token, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isSymbol("("):
+			depth++
+		case tokens[i].isSymbol(")"):
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+
+	return 0, errors.New("unbalanced parentheses")
+}
+
+// joinTokens 将词法单元还原为sql文本
+func joinTokens(tokens []token) string {
+	var buf strings.Builder
+	for i, t := range tokens {
+		if i > 0 && !t.isSymbol(")") && !t.isSymbol(",") && !t.isSymbol("(") && !tokens[i-1].isSymbol("(") {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(t.raw())
+	}
+
+	return buf.String()
+}
+
+// parseCreateTable 解析单条CREATE TABLE语句，非建表语句返回false
+func parseCreateTable(stmt []token) (schema.Table, bool, error) {
+	i := 0
+	if i >= len(stmt) || !stmt[i].is("CREATE") {
+		return schema.Table{}, false, nil
+	}
+	i++
+	if i < len(stmt) && stmt[i].is("TEMPORARY") {
+		i++
+	}
+	if i >= len(stmt) || !stmt[i].is("TABLE") {
+		return schema.Table{}, false, nil
+	}
+	i++
+	if i+2 < len(stmt) && stmt[i].is("IF") && stmt[i+1].is("NOT") && stmt[i+2].is("EXISTS") {
+		i += 3
+	}
+
+	// 表名，可能带库名前缀
+	if i >= len(stmt) {
+		return schema.Table{}, false, errors.New("create table: missing table name")
+	}
+	table := schema.Table{Name: stmt[i].text}
+	i++
+	if i+1 < len(stmt) && stmt[i].isSymbol(".") {
+		table.Name = stmt[i+1].text
+		i += 2
+	}
+
+	// CREATE TABLE ... LIKE / AS SELECT 无法获取字段信息
+	if i >= len(stmt) || !stmt[i].isSymbol("(") {
+		return schema.Table{}, false, errors.Errorf("create table %s: column definitions not found", table.Name)
+	}
+	end, err := closeParen(stmt, i)
+	if err != nil {
+		return schema.Table{}, false, errors.WithMessage(err, "create table "+table.Name)
+	}
+
+	indexes := make([]schema.TableIndex, 0)
+	foreignKeys := make([][]token, 0)
+	for _, def := range splitTopLevel(stmt[i+1 : end]) {
+		if len(def) == 0 {
+			continue
+		}
+		if isConstraint(def) {
+			index, ok := parseIndex(def)
+			if ok {
+				indexes = append(indexes, index)
+			} else if isForeignKey(def) {
+				foreignKeys = append(foreignKeys, def)
+			}
+			continue
+		}
+		column, key, err := parseColumn(def)
+		if err != nil {
+			return schema.Table{}, false, errors.WithMessage(err, "create table "+table.Name)
+		}
+		column.OrdinalPosition = int64(len(table.Columns) + 1)
+		// 字段上声明的主键、唯一键
+		switch key {
+		case "PRI":
+			indexes = append(indexes, schema.TableIndex{Name: schema.PrimaryKey, Unique: true, Type: "BTREE", Columns: []schema.IndexColumn{{Name: column.ColumnName}}})
+		case "UNI":
+			indexes = append(indexes, schema.TableIndex{Unique: true, Type: "BTREE", Columns: []schema.IndexColumn{{Name: column.ColumnName}}})
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	unnamed := 0
+	for _, def := range foreignKeys {
+		fk, ok := parseForeignKey(def)
+		if !ok {
+			continue
+		}
+		// 未指定约束名时与MySQL一致命名为 <表名>_ibfk_<序号>
+		fk.Table = table.Name
+		if fk.Name == "" {
+			unnamed++
+			fk.Name = fmt.Sprintf("%s_ibfk_%d", table.Name, unnamed)
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fk)
+	}
+	sort.SliceStable(table.ForeignKeys, func(i, j int) bool { return table.ForeignKeys[i].Name < table.ForeignKeys[j].Name })
+
+	// 外键字段没有可用索引时MySQL自动创建索引
+	for _, def := range foreignKeys {
+		columns := indexColumns(def)
+		if len(columns) > 0 && !hasLeftPrefixIndex(indexes, columns) {
+			index := schema.TableIndex{Name: constraintName(def), Type: "BTREE"}
+			for _, column := range columns {
+				index.Columns = append(index.Columns, schema.IndexColumn{Name: column})
+			}
+			indexes = append(indexes, index)
+		}
+	}
+	table.Indexes = nameIndexes(indexes)
+
+	keys := schema.ColumnKeys(table.Indexes)
+	for k := range table.Columns {
+		column := &table.Columns[k]
+		column.ColumnKey = sql.NullString{String: keys[strings.ToLower(column.ColumnName)], Valid: true}
+		if column.ColumnKey.String == "PRI" {
+			column.IsNullable = "NO"
+		}
+	}
+
+	// 表选项
+	options := stmt[end+1:]
+	for k := 0; k < len(options); k++ {
+		if !options[k].is("COMMENT") {
+			continue
+		}
+		if k+1 < len(options) && options[k+1].isSymbol("=") {
+			k++
+		}
+		if k+1 < len(options) && options[k+1].kind == tokenString {
+			table.Comment = options[k+1].text
+		}
+		break
+	}
+
+	return table, true, nil
+}
+
+// isConstraint 判断是否为表级索引、约束定义
+func isConstraint(def []token) bool {
+	if def[0].kind != tokenIdent {
+		return false
+	}
+	switch strings.ToUpper(def[0].text) {
+	case "PRIMARY", "UNIQUE", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "CONSTRAINT", "FOREIGN", "CHECK":
+		return true
+	}
+
+	return false
+}
+
+// isForeignKey 判断是否为外键定义
+func isForeignKey(def []token) bool {
+	for _, t := range def {
+		if t.isSymbol("(") {
+			break
+		}
+		if t.is("FOREIGN") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseForeignKey 解析外键定义：[CONSTRAINT [name]] FOREIGN KEY [index_name] (cols) REFERENCES table (cols) [ON DELETE rule] [ON UPDATE rule]
+func parseForeignKey(def []token) (schema.ForeignKey, bool) {
+	fk := schema.ForeignKey{Name: constraintName(def), OnUpdate: "NO ACTION", OnDelete: "NO ACTION"}
+	fk.Columns = indexColumns(def)
+
+	i := 0
+	for i < len(def) && !def[i].is("REFERENCES") {
+		i++
+	}
+	if i+1 >= len(def) || len(fk.Columns) == 0 {
+		return fk, false
+	}
+	i++
+	fk.ReferencedTable = def[i].text
+	if i+2 < len(def) && def[i+1].isSymbol(".") {
+		i += 2
+		fk.ReferencedTable = def[i].text
+	}
+	fk.ReferencedColumns = indexColumns(def[i:])
+
+	for ; i < len(def); i++ {
+		if !def[i].is("ON") || i+2 >= len(def) {
+			continue
+		}
+		rule := strings.ToUpper(def[i+2].text)
+		// SET NULL、SET DEFAULT、NO ACTION
+		if i+3 < len(def) && (def[i+2].is("SET") || def[i+2].is("NO")) {
+			rule += " " + strings.ToUpper(def[i+3].text)
+		}
+		if def[i+1].is("DELETE") {
+			fk.OnDelete = rule
+		} else if def[i+1].is("UPDATE") {
+			fk.OnUpdate = rule
+		}
+	}
+
+	return fk, true
+}
+
+// constraintName 返回 CONSTRAINT 指定的约束名，未指定返回空
+func constraintName(def []token) string {
+	if len(def) > 1 && def[0].is("CONSTRAINT") && !isKeywordToken(def[1]) {
+		return def[1].text
+	}
+
+	return ""
+}
+
+// isKeywordToken 判断是否为约束定义中的关键字
+func isKeywordToken(t token) bool {
+	for _, keyword := range []string{"PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "KEY", "INDEX", "USING"} {
+		if t.is(keyword) {
+			return true
+		}
+	}
+
+	return t.kind == tokenSymbol
+}
+
+// parseIndex 解析表级主键、索引定义，外键、检查约束返回false
+func parseIndex(def []token) (schema.TableIndex, bool) {
+	index := schema.TableIndex{Type: "BTREE"}
+	symbol := constraintName(def)
+	i := 0
+	if def[0].is("CONSTRAINT") {
+		i++
+		if symbol != "" {
+			i++
+		}
+	}
+	if i >= len(def) {
+		return index, false
+	}
+
+	switch {
+	case def[i].is("PRIMARY"):
+		index.Name, index.Unique = schema.PrimaryKey, true
+		i++
+	case def[i].is("UNIQUE"):
+		index.Name, index.Unique = symbol, true
+		i++
+	case def[i].is("FULLTEXT") || def[i].is("SPATIAL"):
+		index.Type = strings.ToUpper(def[i].text)
+		i++
+	case def[i].is("KEY") || def[i].is("INDEX"):
+	default:
+		return index, false
+	}
+	if i < len(def) && (def[i].is("KEY") || def[i].is("INDEX")) {
+		i++
+	}
+	// 索引名
+	if i < len(def) && !def[i].isSymbol("(") && !def[i].is("USING") {
+		if index.Name != schema.PrimaryKey {
+			index.Name = def[i].text
+		}
+		i++
+	}
+	if i+1 < len(def) && def[i].is("USING") {
+		index.Type = strings.ToUpper(def[i+1].text)
+		i += 2
+	}
+	if i >= len(def) || !def[i].isSymbol("(") {
+		return index, false
+	}
+	end, err := closeParen(def, i)
+	if err != nil {
+		return index, false
+	}
+	for _, part := range splitTopLevel(def[i+1 : end]) {
+		if len(part) == 0 {
+			continue
+		}
+		// 函数索引：((expr)) 记录表达式
+		if part[0].isSymbol("(") {
+			if end, err := closeParen(part, 0); err == nil {
+				index.Columns = append(index.Columns, schema.IndexColumn{Expression: joinTokens(part[1:end])})
+			}
+			continue
+		}
+		if part[0].kind != tokenIdent && part[0].kind != tokenQuoted {
+			continue
+		}
+		column := schema.IndexColumn{Name: part[0].text}
+		if len(part) > 2 && part[1].isSymbol("(") {
+			column.Length, _ = strconv.ParseInt(part[2].text, 10, 64)
+		}
+		index.Columns = append(index.Columns, column)
+	}
+
+	// 索引选项
+	for k := end + 1; k < len(def); k++ {
+		switch {
+		case def[k].is("USING") && k+1 < len(def):
+			index.Type = strings.ToUpper(def[k+1].text)
+			k++
+		case def[k].is("COMMENT") && k+1 < len(def) && def[k+1].kind == tokenString:
+			index.Comment = def[k+1].text
+			k++
+		}
+	}
+
+	return index, len(index.Columns) > 0
+}
+
+// indexColumns 返回定义中第一对括号内的字段名
+func indexColumns(def []token) []string {
+	start := -1
+	for k, t := range def {
+		if t.isSymbol("(") {
+			start = k
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+	end, err := closeParen(def, start)
+	if err != nil {
+		return nil
+	}
+
+	columns := make([]string, 0)
+	for _, part := range splitTopLevel(def[start+1 : end]) {
+		if len(part) > 0 && (part[0].kind == tokenIdent || part[0].kind == tokenQuoted) {
+			columns = append(columns, part[0].text)
+		}
+	}
+
+	return columns
+}
+
+// hasLeftPrefixIndex 判断是否存在以指定字段为最左前缀的索引
+func hasLeftPrefixIndex(indexes []schema.TableIndex, columns []string) bool {
+	for _, index := range indexes {
+		if len(index.Columns) < len(columns) {
+			continue
+		}
+		match := true
+		for k, column := range columns {
+			if !strings.EqualFold(index.Columns[k].Name, column) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nameIndexes 未命名的索引按MySQL规则使用首字段名，重名时添加 _2、_3 后缀，主键排在最前
+func nameIndexes(indexes []schema.TableIndex) []schema.TableIndex {
+	used := make(map[string]bool)
+	for _, index := range indexes {
+		used[strings.ToLower(index.Name)] = index.Name != ""
+	}
+
+	ret := make([]schema.TableIndex, 0, len(indexes))
+	for _, index := range indexes {
+		if index.Name == "" {
+			// 函数索引首字段没有字段名，与MySQL一致命名为 functional_index
+			base := index.Columns[0].Name
+			if base == "" {
+				base = "functional_index"
+			}
+			name := base
+			for k := 2; used[strings.ToLower(name)]; k++ {
+				name = fmt.Sprintf("%s_%d", base, k)
+			}
+			index.Name = name
+			used[strings.ToLower(name)] = true
+		}
+		ret = append(ret, index)
+	}
+	schema.SortIndexes(ret)
+
+	return ret
+}
+
+// parseColumn 解析字段定义，返回字段信息及字段上声明的键类型
+func parseColumn(def []token) (schema.TableColumn, string, error) {
+	column := schema.TableColumn{
+		ColumnName:    def[0].text,
+		IsNullable:    "YES",
+		ColumnComment: sql.NullString{Valid: true},
+	}
+	if len(def) < 2 || def[1].kind != tokenIdent {
+		return column, "", errors.Errorf("column %s: missing data type", column.ColumnName)
+	}
+
+	// 数据类型及长度、精度或枚举值
+	column.DataType = strings.ToLower(def[1].text)
+	columnType := column.DataType
+	i := 2
+	if i < len(def) && def[i].isSymbol("(") {
+		end, err := closeParen(def, i)
+		if err != nil {
+			return column, "", errors.WithMessage(err, "column "+column.ColumnName)
+		}
+		args := make([]string, 0)
+		for _, part := range splitTopLevel(def[i+1 : end]) {
+			args = append(args, joinTokens(part))
+		}
+		columnType += "(" + strings.Join(args, ",") + ")"
+		i = end + 1
+	}
+	// double precision
+	if i < len(def) && column.DataType == "double" && def[i].is("PRECISION") {
+		i++
+	}
+	for i < len(def) && (def[i].is("UNSIGNED") || def[i].is("SIGNED") || def[i].is("ZEROFILL")) {
+		if !def[i].is("SIGNED") {
+			columnType += " " + strings.ToLower(def[i].text)
+		}
+		i++
+	}
+	column.ColumnType = normalizeDataType(&column.DataType, columnType)
+
+	key := ""
+	for ; i < len(def); i++ {
+		t := def[i]
+		switch {
+		case t.is("NOT") && i+1 < len(def) && def[i+1].is("NULL"):
+			column.IsNullable = "NO"
+			i++
+		case t.is("NULL"):
+			column.IsNullable = "YES"
+		case t.is("DEFAULT") && i+1 < len(def):
+			value, n := parseDefault(def[i+1:])
+			column.ColumnDefault = value
+			// 表达式及 CURRENT_TIMESTAMP 默认值，与MySQL 8 information_schema中的 DEFAULT_GENERATED 一致
+			_, timestamp := schema.CurrentTimestamp(value.String)
+			column.DefaultExpr = def[i+1].isSymbol("(") || (def[i+1].kind == tokenIdent && timestamp)
+			i += n
+		case t.is("AUTO_INCREMENT"):
+			column.AutoIncrement = true
+		case t.is("ON") && i+2 < len(def) && def[i+1].is("UPDATE"):
+			value, n := parseDefault(def[i+2:])
+			column.OnUpdate = value.String
+			i += n + 1
+		case t.is("COMMENT") && i+1 < len(def) && def[i+1].kind == tokenString:
+			column.ColumnComment = sql.NullString{String: def[i+1].text, Valid: true}
+			i++
+		case t.is("PRIMARY") && i+1 < len(def) && def[i+1].is("KEY"):
+			key = "PRI"
+			i++
+		case t.is("KEY"):
+			key = "PRI"
+		case t.is("UNIQUE"):
+			if key != "PRI" {
+				key = "UNI"
+			}
+			if i+1 < len(def) && def[i+1].is("KEY") {
+				i++
+			}
+		case t.is("CHARACTER") && i+2 < len(def) && def[i+1].is("SET"):
+			i += 2
+		case t.is("CHARSET") || t.is("COLLATE") || t.is("COLUMN_FORMAT") || t.is("STORAGE") || t.is("SRID"):
+			i++
+		case t.is("GENERATED") || t.is("AS"):
+			// 生成列：GENERATED ALWAYS AS (expr) [VIRTUAL|STORED]
+			for i < len(def) && !def[i].isSymbol("(") {
+				i++
+			}
+			if end, err := closeParen(def, i); err == nil {
+				i = end
+			}
+			column.Generated = schema.GeneratedVirtual
+			if i+1 < len(def) && def[i+1].is("STORED") {
+				column.Generated = schema.GeneratedStored
+				i++
+			} else if i+1 < len(def) && def[i+1].is("VIRTUAL") {
+				i++
+			}
+		case t.is("CHECK") && i+1 < len(def) && def[i+1].isSymbol("("):
+			if end, err := closeParen(def, i+1); err == nil {
+				i = end
+			}
+		case t.is("REFERENCES"):
+			// 字段级外键定义，忽略剩余部分
+			i = len(def)
+		}
+	}
+	return column, key, nil
+}
+
+// normalizeDataType 统一类型别名，与information_schema中的写法保持一致
+func normalizeDataType(dataType *string, columnType string) string {
+	aliases := map[string]string{
+		"integer":   "int",
+		"bool":      "tinyint",
+		"boolean":   "tinyint",
+		"int1":      "tinyint",
+		"int2":      "smallint",
+		"int3":      "mediumint",
+		"int4":      "int",
+		"int8":      "bigint",
+		"middleint": "mediumint",
+		"dec":       "decimal",
+		"numeric":   "decimal",
+		"fixed":     "decimal",
+		"real":      "double",
+		"float4":    "float",
+		"float8":    "double",
+	}
+	alias, ok := aliases[*dataType]
+	if !ok {
+		return columnType
+	}
+	columnType = alias + strings.TrimPrefix(columnType, *dataType)
+	if *dataType == "bool" || *dataType == "boolean" {
+		columnType = "tinyint(1)"
+	}
+	*dataType = alias
+
+	return columnType
+}
+
+// parseDefault 解析默认值表达式，返回默认值及额外消耗的词法单元数
+func parseDefault(tokens []token) (sql.NullString, int) {
+	t := tokens[0]
+	switch {
+	case t.is("NULL"):
+		return sql.NullString{}, 1
+	case t.kind == tokenString:
+		return sql.NullString{String: t.text, Valid: true}, 1
+	case t.isSymbol("("):
+		end, err := closeParen(tokens, 0)
+		if err != nil {
+			return sql.NullString{}, len(tokens)
+		}
+		return sql.NullString{String: joinTokens(tokens[1:end]), Valid: true}, end + 1
+	case (t.isSymbol("-") || t.isSymbol("+")) && len(tokens) > 1:
+		value := t.text + tokens[1].text
+		n := 2
+		// 小数：-1.5 被拆分为 - 1 . 5
+		if len(tokens) > 3 && tokens[2].isSymbol(".") {
+			value += "." + tokens[3].text
+			n = 4
+		}
+		return sql.NullString{String: strings.TrimPrefix(value, "+"), Valid: true}, n
+	case (t.is("b") || t.is("x")) && len(tokens) > 1 && tokens[1].kind == tokenString:
+		// 位值、十六进制字面量
+		return sql.NullString{String: fmt.Sprintf("%s'%s'", strings.ToLower(t.text), tokens[1].text), Valid: true}, 2
+	}
+
+	value := t.text
+	n := 1
+	if len(tokens) > 2 && tokens[1].isSymbol(".") {
+		value += "." + tokens[2].text
+		n = 3
+	}
+	// CURRENT_TIMESTAMP(3)、NOW() 等函数
+	if len(tokens) > n && tokens[n].isSymbol("(") {
+		if end, err := closeParen(tokens, n); err == nil {
+			value += "(" + joinTokens(tokens[n+1:end]) + ")"
+			n = end + 1
+		}
+	}
+	// NOW()、LOCALTIMESTAMP 等同义词统一为 CURRENT_TIMESTAMP
+	if t.kind == tokenIdent {
+		value, _ = schema.CurrentTimestamp(value)
+	}
+
+	return sql.NullString{String: value, Valid: true}, n
+}
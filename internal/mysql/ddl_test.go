@@ -0,0 +1,235 @@
+package mysql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"tool-cli/internal/schema"
+)
+
+// column 构造建表语句解析出的字段，未设置的属性与 parseColumn 的默认值一致
+func column(position int64, name string, columnType string, key string, nullable string) schema.TableColumn {
+	dataType := columnType
+	for k, c := range columnType {
+		if c == '(' || c == ' ' {
+			dataType = columnType[:k]
+			break
+		}
+	}
+
+	return schema.TableColumn{
+		OrdinalPosition: position,
+		ColumnName:      name,
+		ColumnType:      columnType,
+		DataType:        dataType,
+		ColumnKey:       sql.NullString{String: key, Valid: true},
+		IsNullable:      nullable,
+		ColumnComment:   sql.NullString{Valid: true},
+	}
+}
+
+// index 构造索引，字段格式同 IndexColumn
+func index(name string, unique bool, kind string, columns ...schema.IndexColumn) schema.TableIndex {
+	return schema.TableIndex{Name: name, Unique: unique, Type: kind, Columns: columns}
+}
+
+func TestParseDdl(t *testing.T) {
+	// user 表字段
+	userID := column(1, "id", "bigint unsigned", "PRI", "NO")
+	userID.AutoIncrement = true
+	userName := column(2, "name", "varchar(32)", "MUL", "NO")
+	userName.ColumnDefault = sql.NullString{String: "", Valid: true}
+	userName.ColumnComment.String = "名称"
+	userEmail := column(3, "email", "varchar(64)", "UNI", "YES")
+	userCreated := column(4, "created_at", "datetime(3)", "", "NO")
+	userCreated.ColumnDefault = sql.NullString{String: "CURRENT_TIMESTAMP(3)", Valid: true}
+	userCreated.DefaultExpr = true
+	userCreated.OnUpdate = "CURRENT_TIMESTAMP(3)"
+	user := schema.Table{
+		Name:    "user",
+		Comment: "用户",
+		Columns: []schema.TableColumn{userID, userName, userEmail, userCreated},
+		Indexes: []schema.TableIndex{
+			index(schema.PrimaryKey, true, "BTREE", schema.IndexColumn{Name: "id"}),
+			index("email", true, "BTREE", schema.IndexColumn{Name: "email"}),
+			index("idx_name", false, "BTREE", schema.IndexColumn{Name: "name", Length: 10}),
+		},
+	}
+
+	// post 表引用 user 表
+	fk := schema.ForeignKey{
+		Name:              "fk_post_user",
+		Table:             "post",
+		Columns:           []string{"user_id"},
+		ReferencedTable:   "user",
+		ReferencedColumns: []string{"id"},
+		OnUpdate:          "NO ACTION",
+		OnDelete:          "CASCADE",
+	}
+	post := schema.Table{
+		Name: "post",
+		Columns: []schema.TableColumn{
+			func() schema.TableColumn {
+				c := column(1, "id", "int", "PRI", "NO")
+				c.AutoIncrement = true
+				return c
+			}(),
+			column(2, "user_id", "bigint unsigned", "MUL", "NO"),
+		},
+		Indexes: []schema.TableIndex{
+			index(schema.PrimaryKey, true, "BTREE", schema.IndexColumn{Name: "id"}),
+			index("fk_post_user", false, "BTREE", schema.IndexColumn{Name: "user_id"}),
+		},
+		ForeignKeys: []schema.ForeignKey{fk},
+	}
+	userReferenced := user
+	userReferenced.ReferencedBy = []schema.ForeignKey{fk}
+
+	// 保留字作为表名、字段名
+	order := schema.Table{
+		Name: "order",
+		Columns: []schema.TableColumn{
+			column(1, "key", "int", "PRI", "NO"),
+			column(2, "desc", "text", "", "YES"),
+			func() schema.TableColumn {
+				c := column(3, "index", "varchar(16)", "MUL", "YES")
+				c.ColumnComment.String = "-- 不是注释"
+				return c
+			}(),
+		},
+		Indexes: []schema.TableIndex{
+			index(schema.PrimaryKey, true, "BTREE", schema.IndexColumn{Name: "key"}),
+			index("index", false, "BTREE", schema.IndexColumn{Name: "index"}),
+		},
+	}
+
+	// 生成列及函数索引
+	fullName := column(3, "full_name", "varchar(65)", "", "YES")
+	fullName.Generated = schema.GeneratedStored
+	person := schema.Table{
+		Name: "person",
+		Columns: []schema.TableColumn{
+			column(1, "first", "varchar(32)", "MUL", "YES"),
+			column(2, "last", "varchar(32)", "", "YES"),
+			fullName,
+		},
+		Indexes: []schema.TableIndex{
+			index("functional_index", false, "BTREE", schema.IndexColumn{Expression: "lower(`last`)"}),
+			index("idx_first_last", false, "BTREE", schema.IndexColumn{Name: "first"}, schema.IndexColumn{Name: "last", Length: 4}),
+		},
+	}
+
+	userDdl := "CREATE TABLE IF NOT EXISTS `db`.`user` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(32) NOT NULL DEFAULT '' COMMENT '名称',\n" +
+		"  `email` varchar(64) UNIQUE,\n" +
+		"  `created_at` datetime(3) NOT NULL DEFAULT now(3) ON UPDATE CURRENT_TIMESTAMP(3),\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx_name` (`name`(10))\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户';"
+	postDdl := "CREATE TABLE post (\n" +
+		"  id int NOT NULL AUTO_INCREMENT PRIMARY KEY,\n" +
+		"  user_id bigint unsigned NOT NULL,\n" +
+		"  CONSTRAINT fk_post_user FOREIGN KEY (user_id) REFERENCES `user` (id) ON DELETE CASCADE\n" +
+		");"
+
+	tests := []struct {
+		name string
+		ddl  string
+		want []schema.Table
+	}{
+		{
+			name: "columns indexes and comment",
+			ddl:  userDdl,
+			want: []schema.Table{user},
+		},
+		{
+			name: "multiple statements with foreign key",
+			ddl: "SET NAMES utf8mb4;\n" +
+				"DROP TABLE IF EXISTS `user`;\n" +
+				"/* 用户表 */\n" + userDdl + "\n" +
+				"# 文章表\n" + postDdl + "\n" +
+				"INSERT INTO `user` VALUES (1, 'a;b', 'x@y', now());\n",
+			want: []schema.Table{userReferenced, post},
+		},
+		{
+			name: "backticked reserved words",
+			ddl: "CREATE TABLE `order` (\n" +
+				"  `key` int NOT NULL,\n" +
+				"  `desc` text,\n" +
+				"  `index` varchar(16) COMMENT '-- 不是注释',\n" +
+				"  PRIMARY KEY (`key`),\n" +
+				"  INDEX (`index`)\n" +
+				")",
+			want: []schema.Table{order},
+		},
+		{
+			name: "generated column and functional index",
+			ddl: "CREATE TABLE person (\n" +
+				"  first varchar(32),\n" +
+				"  last varchar(32),\n" +
+				"  full_name varchar(65) GENERATED ALWAYS AS (concat(first, ' ', last)) STORED,\n" +
+				"  KEY idx_first_last (first, last(4)),\n" +
+				"  INDEX ((lower(`last`)))\n" +
+				");",
+			want: []schema.Table{person},
+		},
+		{
+			name: "dash comment at end of input",
+			ddl:  "CREATE TABLE `order` (`key` int NOT NULL, `desc` text, `index` varchar(16) COMMENT '-- 不是注释', PRIMARY KEY (`key`), INDEX (`index`));\n--",
+			want: []schema.Table{order},
+		},
+		{
+			name: "dash comment followed by tab and carriage return",
+			ddl:  "--\tcomment\r\nCREATE TABLE `order` (--\r\n`key` int NOT NULL, `desc` text, `index` varchar(16) COMMENT '-- 不是注释', PRIMARY KEY (`key`), INDEX (`index`))--",
+			want: []schema.Table{order},
+		},
+		{
+			name: "no create table",
+			ddl:  "-- only comments\nSELECT 1;",
+			want: []schema.Table{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDdl(tt.ddl)
+			if err != nil {
+				t.Fatalf("ParseDdl() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseDdl() tables = %d, want %d", len(got), len(tt.want))
+			}
+			for k := range got {
+				if !reflect.DeepEqual(got[k].Columns, tt.want[k].Columns) {
+					t.Errorf("ParseDdl() %s columns =\n%+v\nwant\n%+v", got[k].Name, got[k].Columns, tt.want[k].Columns)
+				}
+				if !reflect.DeepEqual(got[k].Indexes, tt.want[k].Indexes) {
+					t.Errorf("ParseDdl() %s indexes =\n%+v\nwant\n%+v", got[k].Name, got[k].Indexes, tt.want[k].Indexes)
+				}
+				if !reflect.DeepEqual(got[k], tt.want[k]) {
+					t.Errorf("ParseDdl() %s =\n%+v\nwant\n%+v", got[k].Name, got[k], tt.want[k])
+				}
+			}
+		})
+	}
+}
+
+func TestParseDdlErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ddl  string
+	}{
+		{"unterminated comment", "/* CREATE TABLE t (id int);"},
+		{"unterminated string", "CREATE TABLE t (id int COMMENT 'x);"},
+		{"unbalanced parentheses", "CREATE TABLE t (id int;"},
+		{"create table like", "CREATE TABLE t LIKE s;"},
+		{"missing data type", "CREATE TABLE t (id);"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDdl(tt.ddl); err == nil {
+				t.Errorf("ParseDdl(%q) error = nil, want error", tt.ddl)
+			}
+		})
+	}
+}
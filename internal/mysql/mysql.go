@@ -5,12 +5,21 @@
 package mysql
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"fmt"
+	driver "github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/schema"
+	gormschema "gorm.io/gorm/schema"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"tool-cli/internal/schema"
 )
 
 var _ Repo = (*dbRepo)(nil)
@@ -25,6 +34,17 @@ type Config struct {
 	User     string // 用户名
 	Password string // 密码
 	DbName   string // 数据库名
+	// 完整dsn，例：root:123456@tcp(127.0.0.1:3306)/shop?collation=utf8mb4_bin，指定后忽略以上连接参数及 Socket、超时、Params，TLS证书仍然生效
+	Dsn          string
+	Socket       string            // unix socket路径，指定后忽略Addr
+	TLS          string            // TLS模式：true、false、skip-verify、preferred，指定证书文件时默认为true
+	TLSCa        string            // CA证书文件
+	TLSCert      string            // 客户端证书文件，需同时指定TLSKey
+	TLSKey       string            // 客户端私钥文件
+	Timeout      time.Duration     // 连接超时
+	ReadTimeout  time.Duration     // 读超时
+	WriteTimeout time.Duration     // 写超时
+	Params       map[string]string // 其他dsn参数，覆盖默认的 charset=utf8mb4、loc=Local，例：collation、allowCleartextPasswords
 }
 
 type dbRepo struct {
@@ -33,27 +53,154 @@ type dbRepo struct {
 
 // New
 //
-//	@Description: 连接mysql数据库
+//	@Description: 连接mysql数据库，返回数据库名，指定dsn时取自dsn
 //	@Auth shigx 2024-05-14 16:48:43
 //	@param config
 //	@return Repo
+//	@return string 数据库名
 //	@return error
-func New(config *Config) (Repo, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=%t&loc=%s",
-		config.User,
-		config.Password,
-		config.Addr,
-		config.DbName,
-		true,
-		"Local")
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		NamingStrategy: schema.NamingStrategy{
+func New(config *Config) (Repo, string, error) {
+	cfg, err := DsnConfig(config)
+	if err != nil {
+		return nil, "", err
+	}
+	repo, err := open(cfg)
+
+	return repo, cfg.DBName, err
+}
+
+// NewWithDsn
+//
+//	@Description: 使用dsn连接mysql数据库，例：root:123456@tcp(127.0.0.1:3306)/shop，返回dsn中的数据库名
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param dsn
+//	@return Repo
+//	@return string 数据库名
+//	@return error
+func NewWithDsn(dsn string) (Repo, string, error) {
+	return New(&Config{Dsn: dsn})
+}
+
+// DsnConfig
+//
+//	@Description: 按配置生成驱动连接配置，未指定dsn时默认 charset=utf8mb4、loc=Local，时间字段均按 time.Time 读取
+//	@Auth shigx 2024-09-02 16:20:48
+//	@param config
+//	@return *driver.Config
+//	@return error
+func DsnConfig(config *Config) (*driver.Config, error) {
+	dsn := config.Dsn
+	if dsn == "" {
+		cfg := driver.NewConfig()
+		cfg.User = config.User
+		cfg.Passwd = config.Password
+		cfg.Net, cfg.Addr = "tcp", config.Addr
+		if config.Socket != "" {
+			cfg.Net, cfg.Addr = "unix", config.Socket
+		}
+		cfg.DBName = config.DbName
+		cfg.Loc = time.Local
+		cfg.Timeout = config.Timeout
+		cfg.ReadTimeout = config.ReadTimeout
+		cfg.WriteTimeout = config.WriteTimeout
+		cfg.Params = map[string]string{"charset": "utf8mb4"}
+		dsn = cfg.FormatDSN()
+
+		// 额外参数追加在最后，同名参数覆盖前面的取值
+		keys := make([]string, 0, len(config.Params))
+		for key := range config.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			sep := "&"
+			if !strings.Contains(dsn, "?") {
+				sep = "?"
+			}
+			dsn += sep + key + "=" + url.QueryEscape(config.Params[key])
+		}
+	}
+
+	cfg, err := driver.ParseDSN(dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "dsn格式错误")
+	}
+	if cfg.DBName == "" {
+		return nil, errors.New("未指定数据库名")
+	}
+	// 时间字段按 time.Time 读取
+	cfg.ParseTime = true
+	if err := setTLS(cfg, config); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// setTLS 按证书文件设置TLS，未指定证书文件时使用TLS模式或dsn中的tls参数
+func setTLS(cfg *driver.Config, config *Config) error {
+	if config.TLSCa == "" && config.TLSCert == "" && config.TLSKey == "" {
+		if config.TLS != "" {
+			// 连接时按模式重新生成TLS配置
+			cfg.TLSConfig, cfg.TLS = config.TLS, nil
+		}
+		return nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if config.TLSCa != "" {
+		pem, err := os.ReadFile(config.TLSCa)
+		if err != nil {
+			return errors.Wrap(err, "读取CA证书失败")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.Errorf("CA证书格式错误：%s", config.TLSCa)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.TLSCert != "" || config.TLSKey != "" {
+		if config.TLSCert == "" || config.TLSKey == "" {
+			return errors.New("客户端证书及私钥需要同时指定")
+		}
+		cert, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return errors.Wrap(err, "读取客户端证书失败")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	switch config.TLS {
+	case "", "true":
+	case "skip-verify":
+		tlsConfig.InsecureSkipVerify = true
+	case "preferred":
+		tlsConfig.InsecureSkipVerify = true
+		cfg.AllowFallbackToPlaintext = true
+	default:
+		return errors.Errorf("指定证书文件时不支持的TLS模式：%s，可选值：true、skip-verify、preferred", config.TLS)
+	}
+	cfg.TLS = tlsConfig
+
+	return nil
+}
+
+// open 按驱动连接配置建立gorm连接
+func open(cfg *driver.Config) (Repo, error) {
+	connector, err := driver.NewConnector(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "dsn格式错误")
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:      sql.OpenDB(connector),
+		DSNConfig: cfg,
+	}), &gorm.Config{
+		NamingStrategy: gormschema.NamingStrategy{
 			SingularTable: true,
 		},
 	})
 
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("[db connection failed] Database name: %s", config.DbName))
+		return nil, errors.Wrap(err, fmt.Sprintf("[db connection failed] Database name: %s", cfg.DBName))
 	}
 	db.Set("gorm:table_options", "CHARSET=utf8mb4")
 	// db = db.Debug()
@@ -98,21 +245,102 @@ func GetTableComment(db *gorm.DB, dbName string, tableName string) (string, erro
 	return comment, nil
 }
 
-// TableColumn @Description 表字段信息定义
-// @Auth shigx
-// @Date 2022/3/24 10:42 下午
-// @param
-// @return
-type TableColumn struct {
-	OrdinalPosition int64          `gorm:"column:ORDINAL_POSITION"` // 字段顺序
-	ColumnName      string         `gorm:"column:COLUMN_NAME"`      // 字段名称
-	ColumnType      string         `gorm:"column:COLUMN_TYPE"`      // 字段类型
-	DataType        string         `gorm:"column:DATA_TYPE"`        // 数据类型
-	ColumnKey       sql.NullString `gorm:"column:COLUMN_KEY"`       // 字段键
-	IsNullable      string         `gorm:"column:IS_NULLABLE"`      // 是否允许为空
-	Extra           sql.NullString `gorm:"column:EXTRA"`            // 额外信息
-	ColumnComment   sql.NullString `gorm:"column:COLUMN_COMMENT"`   // 字段备注
-	ColumnDefault   sql.NullString `gorm:"column:COLUMN_DEFAULT"`   // 字段默认值
+// GetTableRows
+//
+//	@Description: 查询表估算行数，InnoDB 为统计信息中的近似值
+//	@Auth shigx 2024-07-02 10:25:16
+//	@param db
+//	@param dbName
+//	@param tableName
+//	@return sql.NullInt64
+//	@return error
+func GetTableRows(db *gorm.DB, dbName string, tableName string) (sql.NullInt64, error) {
+	var rows sql.NullInt64
+	err := db.Table("information_schema.tables").
+		Select("table_rows").
+		Where("table_schema = ? and table_name = ?", dbName, tableName).
+		Take(&rows).
+		Error
+
+	return rows, err
+}
+
+// GetTableNames
+//
+//	@Description: 查询库中全部数据表名，按表名排序
+//	@Auth shigx 2024-06-05 14:20:11
+//	@param db
+//	@param dbName
+//	@return []string
+//	@return error
+func GetTableNames(db *gorm.DB, dbName string) ([]string, error) {
+	ret := make([]string, 0)
+	err := db.Table("information_schema.tables").
+		Where("table_schema = ? and table_type = ?", dbName, "BASE TABLE").
+		Order("table_name ASC").
+		Pluck("table_name", &ret).
+		Error
+
+	return ret, err
+}
+
+// GetTable
+//
+//	@Description: 查询表备注及字段信息
+//	@Auth shigx 2024-06-03 10:12:30
+//	@param db
+//	@param dbName
+//	@param tableName
+//	@return *schema.Table
+//	@return error
+func GetTable(db *gorm.DB, dbName string, tableName string) (*schema.Table, error) {
+	comment, err := GetTableComment(db, dbName, tableName)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("table %s not found", tableName))
+	}
+	columns, err := GetTableColumn(db, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := GetTableIndex(db, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := GetTableForeignKey(db, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	referencedBy, err := GetReferencingForeignKey(db, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := GetTableRows(db, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.Table{
+		Name:         tableName,
+		Comment:      comment,
+		Columns:      columns,
+		Indexes:      indexes,
+		ForeignKeys:  foreignKeys,
+		ReferencedBy: referencedBy,
+		Rows:         rows,
+	}, nil
+}
+
+// columnRow information_schema.columns 查询结果
+type columnRow struct {
+	OrdinalPosition int64          `gorm:"column:ORDINAL_POSITION"`
+	ColumnName      string         `gorm:"column:COLUMN_NAME"`
+	ColumnType      string         `gorm:"column:COLUMN_TYPE"`
+	DataType        string         `gorm:"column:DATA_TYPE"`
+	ColumnKey       sql.NullString `gorm:"column:COLUMN_KEY"`
+	IsNullable      string         `gorm:"column:IS_NULLABLE"`
+	Extra           sql.NullString `gorm:"column:EXTRA"`
+	ColumnComment   sql.NullString `gorm:"column:COLUMN_COMMENT"`
+	ColumnDefault   sql.NullString `gorm:"column:COLUMN_DEFAULT"`
 }
 
 // GetTableColumn
@@ -122,16 +350,159 @@ type TableColumn struct {
 //	@param db
 //	@param dbName
 //	@param tableName
-//	@return []TableColumn
+//	@return []schema.TableColumn
 //	@return error
-func GetTableColumn(db *gorm.DB, dbName string, tableName string) ([]TableColumn, error) {
-	ret := make([]TableColumn, 0)
+func GetTableColumn(db *gorm.DB, dbName string, tableName string) ([]schema.TableColumn, error) {
+	rows := make([]columnRow, 0)
 	err := db.Table("information_schema.columns").
 		Select(`ORDINAL_POSITION`, `COLUMN_NAME`, `COLUMN_TYPE`, `DATA_TYPE`, `COLUMN_KEY`, `IS_NULLABLE`, `EXTRA`, `COLUMN_COMMENT`, `COLUMN_DEFAULT`).
 		Where("table_schema = ? and table_name = ?", dbName, tableName).
 		Order("ORDINAL_POSITION ASC").
-		Find(&ret).
+		Find(&rows).
 		Error
+	if err != nil {
+		return nil, err
+	}
 
-	return ret, err
+	ret := make([]schema.TableColumn, 0, len(rows))
+	for _, row := range rows {
+		column := schema.TableColumn{
+			OrdinalPosition: row.OrdinalPosition,
+			ColumnName:      row.ColumnName,
+			ColumnType:      row.ColumnType,
+			DataType:        row.DataType,
+			ColumnKey:       row.ColumnKey,
+			IsNullable:      row.IsNullable,
+			ColumnComment:   row.ColumnComment,
+			ColumnDefault:   row.ColumnDefault,
+		}
+		column.SetExtra(row.Extra.String)
+		ret = append(ret, column)
+	}
+
+	return ret, nil
+}
+
+// indexRow information_schema.statistics 查询结果
+type indexRow struct {
+	IndexName    string         `gorm:"column:INDEX_NAME"`
+	NonUnique    int64          `gorm:"column:NON_UNIQUE"`
+	SeqInIndex   int64          `gorm:"column:SEQ_IN_INDEX"`
+	ColumnName   sql.NullString `gorm:"column:COLUMN_NAME"`
+	Expression   sql.NullString `gorm:"column:EXPRESSION"` // 函数索引的表达式，mysql 8.0.13 起提供
+	SubPart      sql.NullInt64  `gorm:"column:SUB_PART"`
+	IndexType    string         `gorm:"column:INDEX_TYPE"`
+	IndexComment string         `gorm:"column:INDEX_COMMENT"`
+}
+
+// GetTableIndex
+//
+//	@Description: 返回表索引信息，主键排在最前，其他索引按索引名排序
+//	@Auth shigx 2024-06-26 11:18:40
+//	@param db
+//	@param dbName
+//	@param tableName
+//	@return []schema.TableIndex
+//	@return error
+func GetTableIndex(db *gorm.DB, dbName string, tableName string) ([]schema.TableIndex, error) {
+	rows := make([]indexRow, 0)
+	// EXPRESSION 字段在低版本中不存在，查询全部字段以兼容
+	err := db.Table("information_schema.statistics").
+		Select("*").
+		Where("table_schema = ? and table_name = ?", dbName, tableName).
+		Order("INDEX_NAME ASC, SEQ_IN_INDEX ASC").
+		Find(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.TableIndex, 0)
+	for _, row := range rows {
+		if len(ret) == 0 || ret[len(ret)-1].Name != row.IndexName {
+			ret = append(ret, schema.TableIndex{
+				Name:    row.IndexName,
+				Unique:  row.NonUnique == 0,
+				Type:    row.IndexType,
+				Comment: row.IndexComment,
+			})
+		}
+		index := &ret[len(ret)-1]
+		// 函数索引没有字段名，记录表达式
+		index.Columns = append(index.Columns, schema.IndexColumn{Name: row.ColumnName.String, Length: row.SubPart.Int64, Expression: row.Expression.String})
+	}
+	schema.SortIndexes(ret)
+
+	return ret, nil
+}
+
+// foreignKeyRow information_schema.key_column_usage 及 referential_constraints 查询结果
+type foreignKeyRow struct {
+	ConstraintName       string `gorm:"column:CONSTRAINT_NAME"`
+	TableName            string `gorm:"column:TABLE_NAME"`
+	ColumnName           string `gorm:"column:COLUMN_NAME"`
+	ReferencedTableName  string `gorm:"column:REFERENCED_TABLE_NAME"`
+	ReferencedColumnName string `gorm:"column:REFERENCED_COLUMN_NAME"`
+	UpdateRule           string `gorm:"column:UPDATE_RULE"`
+	DeleteRule           string `gorm:"column:DELETE_RULE"`
+}
+
+// GetTableForeignKey
+//
+//	@Description: 返回表的外键信息，按约束名排序
+//	@Auth shigx 2024-06-28 15:02:33
+//	@param db
+//	@param dbName
+//	@param tableName
+//	@return []schema.ForeignKey
+//	@return error
+func GetTableForeignKey(db *gorm.DB, dbName string, tableName string) ([]schema.ForeignKey, error) {
+	return getForeignKeys(db, "k.table_schema = ? and k.table_name = ?", dbName, tableName)
+}
+
+// GetReferencingForeignKey
+//
+//	@Description: 返回其他表引用本表的外键信息，按表名、约束名排序
+//	@Auth shigx 2024-06-28 15:02:33
+//	@param db
+//	@param dbName
+//	@param tableName
+//	@return []schema.ForeignKey
+//	@return error
+func GetReferencingForeignKey(db *gorm.DB, dbName string, tableName string) ([]schema.ForeignKey, error) {
+	return getForeignKeys(db, "k.referenced_table_schema = ? and k.referenced_table_name = ?", dbName, tableName)
+}
+
+// getForeignKeys 按条件查询外键信息
+func getForeignKeys(db *gorm.DB, where string, args ...interface{}) ([]schema.ForeignKey, error) {
+	rows := make([]foreignKeyRow, 0)
+	err := db.Table("information_schema.key_column_usage k").
+		Select("k.CONSTRAINT_NAME", "k.TABLE_NAME", "k.COLUMN_NAME", "k.REFERENCED_TABLE_NAME", "k.REFERENCED_COLUMN_NAME", "r.UPDATE_RULE", "r.DELETE_RULE").
+		Joins("JOIN information_schema.referential_constraints r ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name AND r.table_name = k.table_name").
+		Where(where, args...).
+		Where("k.referenced_table_name IS NOT NULL").
+		Order("k.TABLE_NAME ASC, k.CONSTRAINT_NAME ASC, k.ORDINAL_POSITION ASC").
+		Find(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.ForeignKey, 0)
+	for _, row := range rows {
+		if len(ret) == 0 || ret[len(ret)-1].Name != row.ConstraintName || ret[len(ret)-1].Table != row.TableName {
+			ret = append(ret, schema.ForeignKey{
+				Name:            row.ConstraintName,
+				Table:           row.TableName,
+				ReferencedTable: row.ReferencedTableName,
+				OnUpdate:        row.UpdateRule,
+				OnDelete:        row.DeleteRule,
+			})
+		}
+		fk := &ret[len(ret)-1]
+		fk.Columns = append(fk.Columns, row.ColumnName)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, row.ReferencedColumnName)
+	}
+
+	return ret, nil
 }
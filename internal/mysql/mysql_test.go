@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"testing"
+)
+
+func TestDsnConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		dbName string
+		addr   string
+	}{
+		{"连接参数", &Config{Addr: "127.0.0.1:3306", User: "root", DbName: "shop", Params: map[string]string{"collation": "utf8mb4_bin"}}, "shop", "127.0.0.1:3306"},
+		{"socket", &Config{Addr: "127.0.0.1:3306", Socket: "/tmp/mysql.sock", DbName: "shop"}, "shop", "/tmp/mysql.sock"},
+		{"dsn优先", &Config{Addr: "127.0.0.1:3306", DbName: "shop", Dsn: "app:secret@tcp(db:3307)/order"}, "order", "db:3307"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := DsnConfig(tt.config)
+			if err != nil {
+				t.Fatalf("DsnConfig() error = %v", err)
+			}
+			if cfg.DBName != tt.dbName || cfg.Addr != tt.addr {
+				t.Errorf("DsnConfig() db = %q addr = %q, want %q %q", cfg.DBName, cfg.Addr, tt.dbName, tt.addr)
+			}
+		})
+	}
+
+	for _, config := range []*Config{{Addr: "127.0.0.1:3306"}, {Dsn: "root@tcp(db:3306)/"}, {Dsn: "bad dsn"}} {
+		if _, err := DsnConfig(config); err == nil {
+			t.Errorf("DsnConfig(%+v) error = nil, want error", config)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package mysql
+// @Title mysql选项文件
+// @Description 读取mysql客户端的选项文件 ~/.my.cnf 及 mysql_config_editor 生成的加密登录文件 ~/.mylogin.cnf
+// @Author shigx 2024-09-16 15:40:12
+package mysql
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultLoginPath 未指定登录路径时读取的选项组
+const DefaultLoginPath = "client"
+
+// LoginOptions
+//
+//	@Description: 按mysql客户端的规则读取家目录下的 .my.cnf、.mylogin.cnf，依次合并 [client] 及登录路径对应的选项组，后读取的覆盖先读取的，
+//	文件不存在时忽略，返回的选项名统一为小写、下划线形式，例：password、user、host、port、socket
+//	@Auth shigx 2024-09-16 15:40:12
+//	@param loginPath 登录路径，为空时为client
+//	@return map[string]string
+//	@return error
+func LoginOptions(loginPath string) (map[string]string, error) {
+	if loginPath == "" {
+		loginPath = DefaultLoginPath
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]string)
+	for _, file := range []string{".my.cnf", ".mylogin.cnf"} {
+		path := filepath.Join(home, file)
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if file == ".mylogin.cnf" {
+			if content, err = decryptLoginFile(content); err != nil {
+				return nil, errors.Wrap(err, "读取 "+path+" 失败")
+			}
+		}
+		groups := parseOptionFile(content)
+		for _, group := range []string{DefaultLoginPath, loginPath} {
+			for key, value := range groups[group] {
+				ret[key] = value
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// parseOptionFile 解析ini格式的选项文件，返回 选项组 => 选项名 => 值，忽略 !include 等指令
+func parseOptionFile(content []byte) map[string]map[string]string {
+	ret := make(map[string]map[string]string)
+	group := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", line[0] == '#', line[0] == ';', line[0] == '!':
+			continue
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			group = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if ret[group] == nil {
+			ret[group] = make(map[string]string)
+		}
+		ret[group][key] = value
+	}
+
+	return ret
+}
+
+// decryptLoginFile
+//
+//	@Description: 解密 .mylogin.cnf：前4字节保留，随后20字节为密钥，按位异或折叠为16字节的AES-128密钥；
+//	之后每行为4字节小端长度加AES-128-ECB密文，解密去除填充后为明文行
+//	@Auth shigx 2024-09-16 15:40:12
+//	@param content
+//	@return []byte
+//	@return error
+func decryptLoginFile(content []byte) ([]byte, error) {
+	const keyLen = 20
+	if len(content) < 4+keyLen {
+		return nil, errors.New("文件格式错误")
+	}
+	key := make([]byte, aes.BlockSize)
+	for k, b := range content[4 : 4+keyLen] {
+		key[k%aes.BlockSize] ^= b
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := bytes.NewBuffer(nil)
+	data := content[4+keyLen:]
+	for len(data) >= 4 {
+		size := int(binary.LittleEndian.Uint32(data[:4]))
+		data = data[4:]
+		if size > len(data) || size%aes.BlockSize != 0 {
+			return nil, errors.New("文件格式错误")
+		}
+		line := make([]byte, size)
+		for k := 0; k < size; k += aes.BlockSize {
+			block.Decrypt(line[k:k+aes.BlockSize], data[k:k+aes.BlockSize])
+		}
+		data = data[size:]
+		// 去除PKCS#7填充
+		if size > 0 {
+			if pad := int(line[size-1]); pad > 0 && pad <= aes.BlockSize {
+				line = line[:size-pad]
+			}
+		}
+		ret.Write(line)
+	}
+
+	return ret.Bytes(), nil
+}
@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testdata/mylogin.cnf 使用 openssl enc -aes-128-ecb 按 mysql_config_editor 的格式加密，密钥为 0x01..0x14
+const loginFilePlain = "[client]\n" +
+	"user = \"app\"\n" +
+	"password = \"cl1ent\"\n" +
+	"[prod]\n" +
+	"host = \"prod-db\"\n" +
+	"port = 3307\n" +
+	"password = 'pr0d;#x'\n"
+
+func TestDecryptLoginFile(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "mylogin.cnf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decryptLoginFile(content)
+	if err != nil {
+		t.Fatalf("decryptLoginFile() error = %v", err)
+	}
+	if string(got) != loginFilePlain {
+		t.Errorf("decryptLoginFile() = %q, want %q", got, loginFilePlain)
+	}
+
+	for _, bad := range [][]byte{content[:10], append(content[:24:24], 5, 0, 0, 0, 1, 2, 3, 4, 5)} {
+		if _, err := decryptLoginFile(bad); err == nil {
+			t.Errorf("decryptLoginFile(%d bytes) error = nil, want format error", len(bad))
+		}
+	}
+}
+
+func TestLoginOptions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	myCnf := "# 本地配置\n" +
+		"[client]\n" +
+		"user=local\n" +
+		"host=localhost\n" +
+		"!includedir /etc/mysql/conf.d/\n" +
+		"[mysqldump]\n" +
+		"quick\n" +
+		"[prod]\n" +
+		"default-character-set = utf8mb4\n" +
+		"socket=/tmp/prod.sock\n"
+	if err := os.WriteFile(filepath.Join(home, ".my.cnf"), []byte(myCnf), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只有 .my.cnf
+	got, err := LoginOptions("")
+	if err != nil {
+		t.Fatalf("LoginOptions() error = %v", err)
+	}
+	want := map[string]string{"user": "local", "host": "localhost"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoginOptions() = %v, want %v", got, want)
+	}
+
+	// .mylogin.cnf 覆盖 .my.cnf，登录路径覆盖 [client]
+	content, err := os.ReadFile(filepath.Join("testdata", "mylogin.cnf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".mylogin.cnf"), content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		loginPath string
+		want      map[string]string
+	}{
+		{"", map[string]string{"user": "app", "host": "localhost", "password": "cl1ent"}},
+		{"prod", map[string]string{
+			"user":                  "app",
+			"host":                  "prod-db",
+			"port":                  "3307",
+			"socket":                "/tmp/prod.sock",
+			"default_character_set": "utf8mb4",
+			"password":              "pr0d;#x",
+		}},
+	}
+	for _, tt := range tests {
+		got, err := LoginOptions(tt.loginPath)
+		if err != nil {
+			t.Fatalf("LoginOptions(%q) error = %v", tt.loginPath, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LoginOptions(%q) = %v, want %v", tt.loginPath, got, tt.want)
+		}
+	}
+}
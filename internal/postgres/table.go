@@ -0,0 +1,358 @@
+// Package postgres
+// @Description: 从 pg_catalog 读取表、字段、索引、外键及备注，需要 PostgreSQL 12 及以上版本
+// @Auth shigx 2024-08-19 14:26:37
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"regexp"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// DataTypeEnum 枚举类型字段的数据类型，完整类型为枚举类型名
+const DataTypeEnum = "enum"
+
+// GetTableNames
+//
+//	@Description: 查询schema中全部数据表名，分区表只返回主表，按表名排序
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param db
+//	@param schemaName
+//	@return []string
+//	@return error
+func GetTableNames(db *gorm.DB, schemaName string) ([]string, error) {
+	ret := make([]string, 0)
+	err := db.Raw(`SELECT c.relname FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = ? AND c.relkind IN ('r', 'p') AND NOT c.relispartition
+ORDER BY c.relname ASC`, schemaName).
+		Scan(&ret).
+		Error
+
+	return ret, err
+}
+
+// tableRow pg_class 查询结果
+type tableRow struct {
+	Oid     int64
+	Comment string
+	Rows    int64
+}
+
+// GetTable
+//
+//	@Description: 查询表备注、字段、索引及外键信息
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param db
+//	@param schemaName
+//	@param tableName
+//	@return *schema.Table
+//	@return error
+func GetTable(db *gorm.DB, schemaName string, tableName string) (*schema.Table, error) {
+	rows := make([]tableRow, 0)
+	err := db.Raw(`SELECT c.oid::bigint AS oid, COALESCE(obj_description(c.oid, 'pg_class'), '') AS comment, c.reltuples::bigint AS rows
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = ? AND c.relname = ? AND c.relkind IN ('r', 'p')`, schemaName, tableName).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.Errorf("table %s not found", tableName)
+	}
+	oid := rows[0].Oid
+
+	columns, err := GetTableColumn(db, oid)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := GetTableIndex(db, oid)
+	if err != nil {
+		return nil, err
+	}
+	schema.SetColumnKeys(columns, indexes)
+	foreignKeys, err := getForeignKeys(db, "con.conrelid = ?", oid)
+	if err != nil {
+		return nil, err
+	}
+	referencedBy, err := getForeignKeys(db, "con.confrelid = ?", oid)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &schema.Table{
+		Name:         tableName,
+		Comment:      rows[0].Comment,
+		Columns:      columns,
+		Indexes:      indexes,
+		ForeignKeys:  foreignKeys,
+		ReferencedBy: referencedBy,
+	}
+	// 从未 ANALYZE 的表 reltuples 为 -1
+	if rows[0].Rows >= 0 {
+		table.Rows = sql.NullInt64{Int64: rows[0].Rows, Valid: true}
+	}
+
+	return table, nil
+}
+
+// columnRow pg_attribute 查询结果
+type columnRow struct {
+	OrdinalPosition int64
+	ColumnName      string
+	ColumnType      string
+	TypeName        string
+	TypeType        string
+	ElementType     sql.NullString
+	NotNull         bool
+	Identity        string
+	Generated       string
+	ColumnDefault   sql.NullString
+	ColumnComment   sql.NullString
+}
+
+// GetTableColumn
+//
+//	@Description: 返回表字段信息，类型为 format_type 的结果，域类型按基础类型处理
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param db
+//	@param oid 表的oid
+//	@return []schema.TableColumn
+//	@return error
+func GetTableColumn(db *gorm.DB, oid int64) ([]schema.TableColumn, error) {
+	rows := make([]columnRow, 0)
+	err := db.Raw(`SELECT row_number() OVER (ORDER BY a.attnum) AS ordinal_position,
+	a.attname AS column_name,
+	format_type(a.atttypid, a.atttypmod) AS column_type,
+	t.typname AS type_name,
+	t.typtype::text AS type_type,
+	e.typname AS element_type,
+	a.attnotnull AS not_null,
+	a.attidentity::text AS identity,
+	a.attgenerated::text AS generated,
+	pg_get_expr(d.adbin, d.adrelid) AS column_default,
+	col_description(a.attrelid, a.attnum) AS column_comment
+FROM pg_attribute a
+JOIN pg_type dt ON dt.oid = a.atttypid
+JOIN pg_type t ON t.oid = CASE WHEN dt.typtype = 'd' THEN dt.typbasetype ELSE dt.oid END
+LEFT JOIN pg_type e ON e.oid = t.typelem AND t.typcategory = 'A'
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE a.attrelid = ? AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum ASC`, oid).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.TableColumn, 0, len(rows))
+	for _, row := range rows {
+		column := schema.TableColumn{
+			OrdinalPosition: row.OrdinalPosition,
+			ColumnName:      row.ColumnName,
+			ColumnType:      row.ColumnType,
+			DataType:        dataType(row),
+			IsNullable:      "YES",
+			ColumnComment:   row.ColumnComment,
+		}
+		if row.NotNull {
+			column.IsNullable = "NO"
+		}
+		setDefault(&column, row)
+		ret = append(ret, column)
+	}
+
+	return ret, nil
+}
+
+// dataType 返回字段数据类型：基础类型为 pg_type 中的类型名，例：int4、varchar、timestamptz，枚举为 enum，数组为元素类型加 []
+func dataType(row columnRow) string {
+	if row.ElementType.Valid {
+		return row.ElementType.String + "[]"
+	}
+	if row.TypeType == "e" {
+		return DataTypeEnum
+	}
+
+	return row.TypeName
+}
+
+var (
+	// literalRegexp 带类型转换的字符串常量，例：'abc'::character varying
+	literalRegexp = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[\w\s."\[\]()]+)?$`)
+	// numberRegexp 数值常量
+	numberRegexp = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	// sequenceRegexp 序列默认值，serial 类型字段
+	sequenceRegexp = regexp.MustCompile(`^nextval\('.*'::regclass\)$`)
+)
+
+// setDefault
+//
+//	@Description: 设置默认值及自增、生成列：
+//	自增（identity、serial）字段不保留序列默认值，生成列均为 STORED，字符串常量去除引号及类型转换，其他非数值默认值为表达式
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param column
+//	@param row
+func setDefault(column *schema.TableColumn, row columnRow) {
+	value := row.ColumnDefault.String
+	switch {
+	case row.Identity != "" || (row.ColumnDefault.Valid && sequenceRegexp.MatchString(value)):
+		column.AutoIncrement = true
+	case row.Generated != "":
+		column.Generated = schema.GeneratedStored
+	case !row.ColumnDefault.Valid:
+	case literalRegexp.MatchString(value):
+		literal := literalRegexp.FindStringSubmatch(value)[1]
+		column.ColumnDefault = sql.NullString{String: unquote(literal), Valid: true}
+	case numberRegexp.MatchString(value):
+		column.ColumnDefault = row.ColumnDefault
+	default:
+		column.ColumnDefault = row.ColumnDefault
+		column.DefaultExpr = true
+	}
+}
+
+// unquote 还原字符串常量中转义的单引号
+func unquote(s string) string {
+	ret := make([]byte, 0, len(s))
+	for k := 0; k < len(s); k++ {
+		ret = append(ret, s[k])
+		if s[k] == '\'' && k+1 < len(s) && s[k+1] == '\'' {
+			k++
+		}
+	}
+
+	return string(ret)
+}
+
+// indexRow pg_index 查询结果
+type indexRow struct {
+	IndexName    string
+	IsUnique     bool
+	IsPrimary    bool
+	Method       string
+	ColumnName   sql.NullString
+	Expression   sql.NullString
+	IndexComment string
+}
+
+// GetTableIndex
+//
+//	@Description: 返回表索引信息，主键名统一为 PRIMARY 并排在最前，其他索引按索引名排序，表达式索引记录表达式，INCLUDE 字段不输出
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param db
+//	@param oid 表的oid
+//	@return []schema.TableIndex
+//	@return error
+func GetTableIndex(db *gorm.DB, oid int64) ([]schema.TableIndex, error) {
+	rows := make([]indexRow, 0)
+	err := db.Raw(`SELECT i.relname AS index_name,
+	x.indisunique AS is_unique,
+	x.indisprimary AS is_primary,
+	am.amname AS method,
+	a.attname AS column_name,
+	CASE WHEN k.attnum = 0 THEN pg_get_indexdef(x.indexrelid, k.ord::int, true) END AS expression,
+	COALESCE(obj_description(i.oid, 'pg_class'), '') AS index_comment
+FROM pg_index x
+JOIN pg_class i ON i.oid = x.indexrelid
+JOIN pg_am am ON am.oid = i.relam
+CROSS JOIN LATERAL unnest(x.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+LEFT JOIN pg_attribute a ON a.attrelid = x.indrelid AND a.attnum = k.attnum
+WHERE x.indrelid = ? AND k.ord <= x.indnkeyatts
+ORDER BY i.relname ASC, k.ord ASC`, oid).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.TableIndex, 0)
+	for _, row := range rows {
+		name := row.IndexName
+		if row.IsPrimary {
+			name = schema.PrimaryKey
+		}
+		if len(ret) == 0 || ret[len(ret)-1].Name != name {
+			ret = append(ret, schema.TableIndex{
+				Name:    name,
+				Unique:  row.IsUnique,
+				Type:    strings.ToUpper(row.Method), // 与mysql的索引类型一致，例：btree => BTREE
+				Comment: row.IndexComment,
+			})
+		}
+		index := &ret[len(ret)-1]
+		index.Columns = append(index.Columns, schema.IndexColumn{Name: row.ColumnName.String, Expression: row.Expression.String})
+	}
+	schema.SortIndexes(ret)
+
+	return ret, nil
+}
+
+// foreignKeyRow pg_constraint 查询结果
+type foreignKeyRow struct {
+	ConstraintName       string
+	TableName            string
+	ColumnName           string
+	ReferencedTableName  string
+	ReferencedColumnName string
+	UpdateRule           string
+	DeleteRule           string
+}
+
+// foreignKeyActions pg_constraint 中的外键规则
+var foreignKeyActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// getForeignKeys 按条件查询外键信息，按表名、约束名排序
+func getForeignKeys(db *gorm.DB, where string, args ...interface{}) ([]schema.ForeignKey, error) {
+	rows := make([]foreignKeyRow, 0)
+	err := db.Raw(fmt.Sprintf(`SELECT con.conname AS constraint_name,
+	c.relname AS table_name,
+	a.attname AS column_name,
+	rc.relname AS referenced_table_name,
+	ra.attname AS referenced_column_name,
+	con.confupdtype::text AS update_rule,
+	con.confdeltype::text AS delete_rule
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_class rc ON rc.oid = con.confrelid
+CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refnum, ord)
+JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refnum
+WHERE con.contype = 'f' AND %s
+ORDER BY c.relname ASC, con.conname ASC, k.ord ASC`, where), args...).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.ForeignKey, 0)
+	for _, row := range rows {
+		if len(ret) == 0 || ret[len(ret)-1].Name != row.ConstraintName || ret[len(ret)-1].Table != row.TableName {
+			ret = append(ret, schema.ForeignKey{
+				Name:            row.ConstraintName,
+				Table:           row.TableName,
+				ReferencedTable: row.ReferencedTableName,
+				OnUpdate:        foreignKeyActions[row.UpdateRule],
+				OnDelete:        foreignKeyActions[row.DeleteRule],
+			})
+		}
+		fk := &ret[len(ret)-1]
+		fk.Columns = append(fk.Columns, row.ColumnName)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, row.ReferencedColumnName)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,200 @@
+// Package schema
+// @Description: 表结构json、yaml序列化，生成可读取的表结构文档
+// @Auth shigx 2024-07-15 10:40:05
+package schema
+
+import (
+	"database/sql"
+	"encoding/json"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion 表结构文档格式版本，格式不兼容调整时递增
+const SchemaVersion = 2
+
+// Schema @Description 表结构文档，表按表名排序
+// @Auth shigx
+// @Date 2024-07-15 10:40:05
+type Schema struct {
+	Version  int     `json:"version" yaml:"version"`                       // 文档格式版本
+	Driver   string  `json:"driver,omitempty" yaml:"driver,omitempty"`     // 数据库驱动，为空时为mysql
+	Database string  `json:"database,omitempty" yaml:"database,omitempty"` // 数据库名
+	Tables   []Table `json:"tables" yaml:"tables"`                         // 表结构
+}
+
+// tableJSON 表结构json、yaml格式，估算行数为空时不输出
+type tableJSON struct {
+	Name         string        `json:"name" yaml:"name"`
+	Comment      string        `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Rows         *int64        `json:"rows,omitempty" yaml:"rows,omitempty"`
+	Columns      []TableColumn `json:"columns" yaml:"columns"`
+	Indexes      []TableIndex  `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+	ForeignKeys  []ForeignKey  `json:"foreign_keys,omitempty" yaml:"foreign_keys,omitempty"`
+	ReferencedBy []ForeignKey  `json:"referenced_by,omitempty" yaml:"referenced_by,omitempty"`
+}
+
+// MarshalJSON 序列化表结构
+func (t Table) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.toJSON())
+}
+
+func (t Table) toJSON() tableJSON {
+	return tableJSON{
+		Name:         t.Name,
+		Comment:      t.Comment,
+		Rows:         nullInt64Ptr(t.Rows),
+		Columns:      t.Columns,
+		Indexes:      t.Indexes,
+		ForeignKeys:  t.ForeignKeys,
+		ReferencedBy: t.ReferencedBy,
+	}
+}
+
+// MarshalYAML 序列化表结构
+func (t Table) MarshalYAML() (interface{}, error) {
+	return t.toJSON(), nil
+}
+
+// UnmarshalJSON 反序列化表结构
+func (t *Table) UnmarshalJSON(data []byte) error {
+	var v tableJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.fromJSON(v)
+
+	return nil
+}
+
+// UnmarshalYAML 反序列化表结构
+func (t *Table) UnmarshalYAML(value *yaml.Node) error {
+	var v tableJSON
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	t.fromJSON(v)
+
+	return nil
+}
+
+func (t *Table) fromJSON(v tableJSON) {
+	*t = Table{
+		Name:         v.Name,
+		Comment:      v.Comment,
+		Rows:         ptrNullInt64(v.Rows),
+		Columns:      v.Columns,
+		Indexes:      v.Indexes,
+		ForeignKeys:  v.ForeignKeys,
+		ReferencedBy: v.ReferencedBy,
+	}
+}
+
+// columnJSON 字段json、yaml格式，可空值为null时不输出
+type columnJSON struct {
+	OrdinalPosition int64   `json:"position" yaml:"position"`
+	ColumnName      string  `json:"name" yaml:"name"`
+	ColumnType      string  `json:"column_type" yaml:"column_type"`
+	DataType        string  `json:"data_type" yaml:"data_type"`
+	ColumnKey       string  `json:"key,omitempty" yaml:"key,omitempty"`
+	IsNullable      string  `json:"nullable" yaml:"nullable"`
+	ColumnComment   string  `json:"comment,omitempty" yaml:"comment,omitempty"`
+	ColumnDefault   *string `json:"default,omitempty" yaml:"default,omitempty"`
+	AutoIncrement   bool    `json:"auto_increment,omitempty" yaml:"auto_increment,omitempty"`
+	DefaultExpr     bool    `json:"default_expr,omitempty" yaml:"default_expr,omitempty"`
+	OnUpdate        string  `json:"on_update,omitempty" yaml:"on_update,omitempty"`
+	Generated       string  `json:"generated,omitempty" yaml:"generated,omitempty"`
+}
+
+// MarshalJSON 序列化字段信息，默认值为null与空字符串区分输出
+func (c TableColumn) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toJSON())
+}
+
+// MarshalYAML 序列化字段信息
+func (c TableColumn) MarshalYAML() (interface{}, error) {
+	return c.toJSON(), nil
+}
+
+func (c TableColumn) toJSON() columnJSON {
+	return columnJSON{
+		OrdinalPosition: c.OrdinalPosition,
+		ColumnName:      c.ColumnName,
+		ColumnType:      c.ColumnType,
+		DataType:        c.DataType,
+		ColumnKey:       c.ColumnKey.String,
+		IsNullable:      c.IsNullable,
+		ColumnComment:   c.ColumnComment.String,
+		ColumnDefault:   nullStringPtr(c.ColumnDefault),
+		AutoIncrement:   c.AutoIncrement,
+		DefaultExpr:     c.DefaultExpr,
+		OnUpdate:        c.OnUpdate,
+		Generated:       c.Generated,
+	}
+}
+
+// UnmarshalJSON 反序列化字段信息
+func (c *TableColumn) UnmarshalJSON(data []byte) error {
+	var v columnJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	c.fromJSON(v)
+
+	return nil
+}
+
+// UnmarshalYAML 反序列化字段信息
+func (c *TableColumn) UnmarshalYAML(value *yaml.Node) error {
+	var v columnJSON
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	c.fromJSON(v)
+
+	return nil
+}
+
+func (c *TableColumn) fromJSON(v columnJSON) {
+	*c = TableColumn{
+		OrdinalPosition: v.OrdinalPosition,
+		ColumnName:      v.ColumnName,
+		ColumnType:      v.ColumnType,
+		DataType:        v.DataType,
+		ColumnKey:       sql.NullString{String: v.ColumnKey, Valid: true},
+		IsNullable:      v.IsNullable,
+		ColumnComment:   sql.NullString{String: v.ColumnComment, Valid: true},
+		ColumnDefault:   ptrNullString(v.ColumnDefault),
+		AutoIncrement:   v.AutoIncrement,
+		DefaultExpr:     v.DefaultExpr,
+		OnUpdate:        v.OnUpdate,
+		Generated:       v.Generated,
+	}
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func ptrNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+func ptrNullInt64(n *int64) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *n, Valid: true}
+}
@@ -0,0 +1,55 @@
+// Package schema
+// @Description: 与数据库驱动无关的表结构读取接口，支持mysql、postgresql、sqlite、建表语句及快照，数据库连接见 datasource
+// @Auth shigx 2024-08-19 14:26:37
+package schema
+
+// 数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// Drivers 支持的数据库驱动
+var Drivers = []string{DriverMySQL, DriverPostgres, DriverSqlite}
+
+// Reader @Description 表结构读取接口，表结构统一转换为 Table
+// @Auth shigx
+// @Date 2024-08-19 14:26:37
+type Reader interface {
+	Driver() string                    // 数据库驱动，建表语句为mysql，快照为快照中记录的驱动
+	Database() string                  // 数据库名
+	TableNames() ([]string, error)     // 全部表名，按表名排序
+	Table(name string) (*Table, error) // 表结构信息
+	Close() error                      // 关闭数据库连接
+}
+
+// Load
+//
+//	@Description: 加载全部表结构，表按表名排序
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param reader
+//	@return *Schema
+//	@return error
+func Load(reader Reader) (*Schema, error) {
+	names, err := reader.TableNames()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Schema{
+		Version:  SchemaVersion,
+		Driver:   reader.Driver(),
+		Database: reader.Database(),
+		Tables:   make([]Table, 0, len(names)),
+	}
+	for _, name := range names {
+		table, err := reader.Table(name)
+		if err != nil {
+			return nil, err
+		}
+		ret.Tables = append(ret.Tables, *table)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,108 @@
+// Package schema
+// @Description: 表结构快照读取及序列化
+// @Auth shigx 2024-07-22 15:06:41
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 快照格式
+const (
+	SnapshotJSON = "json"
+	SnapshotYAML = "yaml"
+)
+
+// ReadSnapshot
+//
+//	@Description: 读取 schema dump 或 sql2md --format json 生成的快照，.yaml、.yml 为yaml格式，其余为json格式
+//	@Auth shigx 2024-07-22 15:06:41
+//	@param path
+//	@return *Schema
+//	@return error
+func ReadSnapshot(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Schema{}
+	if SnapshotFormat(path) == SnapshotYAML {
+		err = yaml.Unmarshal(content, ret)
+	} else {
+		err = json.Unmarshal(content, ret)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "读取表结构快照失败："+path)
+	}
+	if ret.Version != SchemaVersion {
+		return nil, errors.Errorf("表结构快照 %s 版本为 %d，当前支持的版本为 %d", path, ret.Version, SchemaVersion)
+	}
+	sortTables(ret.Tables)
+
+	return ret, nil
+}
+
+// EncodeSnapshot
+//
+//	@Description: 按格式序列化表结构快照
+//	@Auth shigx 2024-08-12 10:08:55
+//	@param schema
+//	@param format json、yaml
+//	@return []byte
+//	@return error
+func EncodeSnapshot(schema *Schema, format string) ([]byte, error) {
+	switch format {
+	case SnapshotJSON:
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case SnapshotYAML:
+		buf := bytes.NewBufferString("")
+		encoder := yaml.NewEncoder(buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(schema); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	return nil, errors.Errorf("不支持的快照格式：%s，可选值：%s、%s", format, SnapshotJSON, SnapshotYAML)
+}
+
+// SnapshotFormat 按文件扩展名返回快照格式，.yaml、.yml 为yaml，其余为json
+func SnapshotFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return SnapshotYAML
+	}
+
+	return SnapshotJSON
+}
+
+// Table 返回指定表，不存在时返回nil
+func (s *Schema) Table(name string) *Table {
+	for k := range s.Tables {
+		if s.Tables[k].Name == name {
+			return &s.Tables[k]
+		}
+	}
+
+	return nil
+}
+
+// sortTables 按表名排序
+func sortTables(tables []Table) {
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+}
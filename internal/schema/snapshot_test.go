@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sampleSchema 覆盖快照中的各类字段，表未按表名排序
+func sampleSchema() *Schema {
+	valid := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	fk := ForeignKey{Name: "fk_order_user", Table: "order", Columns: []string{"user_id"}, ReferencedTable: "user", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE"}
+
+	return &Schema{
+		Version:  SchemaVersion,
+		Driver:   DriverMySQL,
+		Database: "shop",
+		Tables: []Table{
+			{
+				Name:    "user",
+				Comment: "用户",
+				Rows:    sql.NullInt64{Int64: 42, Valid: true},
+				Columns: []TableColumn{
+					{OrdinalPosition: 1, ColumnName: "id", ColumnType: "bigint unsigned", DataType: "bigint", ColumnKey: valid("PRI"), IsNullable: "NO", ColumnComment: valid(""), AutoIncrement: true},
+					{OrdinalPosition: 2, ColumnName: "name", ColumnType: "varchar(32)", DataType: "varchar", ColumnKey: valid("MUL"), IsNullable: "NO", ColumnComment: valid("名称"), ColumnDefault: valid("")},
+					{OrdinalPosition: 3, ColumnName: "nickname", ColumnType: "varchar(32)", DataType: "varchar", ColumnKey: valid(""), IsNullable: "YES", ColumnComment: valid("")},
+					{OrdinalPosition: 4, ColumnName: "updated_at", ColumnType: "datetime(3)", DataType: "datetime", ColumnKey: valid(""), IsNullable: "NO", ColumnComment: valid(""), ColumnDefault: valid("CURRENT_TIMESTAMP(3)"), DefaultExpr: true, OnUpdate: "CURRENT_TIMESTAMP(3)"},
+					{OrdinalPosition: 5, ColumnName: "name_lower", ColumnType: "varchar(32)", DataType: "varchar", ColumnKey: valid(""), IsNullable: "YES", ColumnComment: valid(""), Generated: GeneratedVirtual},
+				},
+				Indexes: []TableIndex{
+					{Name: PrimaryKey, Unique: true, Type: "BTREE", Columns: []IndexColumn{{Name: "id"}}},
+					{Name: "idx_lower_name", Type: "BTREE", Columns: []IndexColumn{{Expression: "lower(`name`)"}}},
+					{Name: "idx_name", Type: "BTREE", Columns: []IndexColumn{{Name: "name", Length: 10}}, Comment: "名称前缀"},
+				},
+				ReferencedBy: []ForeignKey{fk},
+			},
+			{
+				Name: "order",
+				Columns: []TableColumn{
+					{OrdinalPosition: 1, ColumnName: "id", ColumnType: "bigint", DataType: "bigint", ColumnKey: valid("PRI"), IsNullable: "NO", ColumnComment: valid("")},
+					{OrdinalPosition: 2, ColumnName: "user_id", ColumnType: "bigint unsigned", DataType: "bigint", ColumnKey: valid("MUL"), IsNullable: "NO", ColumnComment: valid("")},
+				},
+				Indexes:     []TableIndex{{Name: PrimaryKey, Unique: true, Type: "BTREE", Columns: []IndexColumn{{Name: "id"}}}},
+				ForeignKeys: []ForeignKey{fk},
+			},
+		},
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	for _, format := range []string{SnapshotJSON, SnapshotYAML} {
+		t.Run(format, func(t *testing.T) {
+			data, err := EncodeSnapshot(sampleSchema(), format)
+			if err != nil {
+				t.Fatalf("EncodeSnapshot() error = %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "schema."+format)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadSnapshot(path)
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+
+			// 读取后表按表名排序
+			want := sampleSchema()
+			sortTables(want.Tables)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ReadSnapshot() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"schema.json", `{"version": 1, "tables": []}`},
+		{"schema.json", `{"version": 3, "tables": []}`},
+		{"schema.json", `{"tables": []}`},
+		{"schema.yaml", "version: 1\ntables: []\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadSnapshot(path); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("当前支持的版本为 %d", SchemaVersion)) {
+			t.Errorf("ReadSnapshot(%s) error = %v, want unsupported version", tt.content, err)
+		}
+	}
+}
+
+func TestEncodeSnapshotFormat(t *testing.T) {
+	if _, err := EncodeSnapshot(sampleSchema(), "xml"); err == nil {
+		t.Error("EncodeSnapshot(xml) error = nil, want unsupported format")
+	}
+}
@@ -0,0 +1,72 @@
+// Package schema
+// @Description: 建表语句、快照表结构读取
+// @Auth shigx 2024-08-19 14:26:37
+package schema
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+)
+
+var _ Reader = (*staticReader)(nil)
+
+// staticReader 已解析的表结构，不连接数据库
+type staticReader struct {
+	driver string
+	dbName string
+	tables map[string]Table
+	names  []string
+}
+
+// NewStatic
+//
+//	@Description: 使用已解析的表结构创建读取接口，用于建表语句及快照
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param driver 表结构来源的数据库驱动，为空时为mysql
+//	@param dbName
+//	@param tables
+//	@return Reader
+func NewStatic(driver string, dbName string, tables []Table) Reader {
+	if driver == "" {
+		driver = DriverMySQL
+	}
+	reader := &staticReader{
+		driver: driver,
+		dbName: dbName,
+		tables: make(map[string]Table, len(tables)),
+	}
+	for _, table := range tables {
+		if _, ok := reader.tables[table.Name]; !ok {
+			reader.names = append(reader.names, table.Name)
+		}
+		reader.tables[table.Name] = table
+	}
+	sort.Strings(reader.names)
+
+	return reader
+}
+
+func (r *staticReader) Driver() string {
+	return r.driver
+}
+
+func (r *staticReader) Database() string {
+	return r.dbName
+}
+
+func (r *staticReader) TableNames() ([]string, error) {
+	return r.names, nil
+}
+
+func (r *staticReader) Table(name string) (*Table, error) {
+	table, ok := r.tables[name]
+	if !ok {
+		return nil, errors.Errorf("table %s not found", name)
+	}
+
+	return &table, nil
+}
+
+func (r *staticReader) Close() error {
+	return nil
+}
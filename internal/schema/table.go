@@ -0,0 +1,236 @@
+// Package schema
+// @Description: 与数据库驱动无关的表结构定义，mysql、postgresql、sqlite、建表语句、go结构体及快照统一转换为此结构
+// @Auth shigx 2024-06-03 10:12:30
+package schema
+
+import (
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrimaryKey 主键索引名，各驱动的主键统一使用此名称
+const PrimaryKey = "PRIMARY"
+
+// 生成列类型
+const (
+	GeneratedVirtual = "VIRTUAL"
+	GeneratedStored  = "STORED"
+)
+
+// Table @Description 表结构信息定义
+// @Auth shigx
+// @Date 2024-06-03 10:12:30
+type Table struct {
+	Name    string        // 表名
+	Comment string        // 表备注
+	Columns []TableColumn // 字段信息
+	Indexes []TableIndex  // 索引信息，主键排在最前
+	// 外键信息
+	ForeignKeys []ForeignKey
+	// 其他表引用本表的外键
+	ReferencedBy []ForeignKey
+	// 估算行数，取自数据库统计信息，建表语句模式为空
+	Rows sql.NullInt64
+}
+
+// TableColumn @Description 表字段信息定义
+// @Auth shigx
+// @Date 2022/3/24 10:42 下午
+// @param
+// @return
+type TableColumn struct {
+	OrdinalPosition int64          // 字段顺序
+	ColumnName      string         // 字段名称
+	ColumnType      string         // 完整字段类型，例：varchar(32)、int unsigned、text[]
+	DataType        string         // 数据类型，不含长度、精度，例：varchar、int4
+	ColumnKey       sql.NullString // 字段键，按索引推断：PRI 主键、UNI 单字段唯一索引、MUL 其他索引的首字段
+	IsNullable      string         // 是否允许为空：YES、NO
+	ColumnComment   sql.NullString // 字段备注
+	ColumnDefault   sql.NullString // 字段默认值，字符串常量不含引号，表达式为原文
+	AutoIncrement   bool           // 是否自增，mysql auto_increment、postgres identity及serial、sqlite INTEGER PRIMARY KEY
+	DefaultExpr     bool           // 默认值是否为表达式，例：CURRENT_TIMESTAMP、now()、uuid()
+	OnUpdate        string         // 更新记录时自动设置的值，例：CURRENT_TIMESTAMP，仅mysql
+	Generated       string         // 生成列类型：VIRTUAL、STORED，普通字段为空
+}
+
+// Extra
+//
+//	@Description: 返回字段的额外信息描述，用于文档及差异对比，例：auto_increment、on update CURRENT_TIMESTAMP、STORED GENERATED
+//	@Auth shigx 2024-09-30 10:26:14
+//	@return string
+func (c TableColumn) Extra() string {
+	parts := make([]string, 0)
+	if c.AutoIncrement {
+		parts = append(parts, "auto_increment")
+	}
+	if c.OnUpdate != "" {
+		parts = append(parts, "on update "+c.OnUpdate)
+	}
+	if c.Generated != "" {
+		parts = append(parts, c.Generated+" GENERATED")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// SetExtra
+//
+//	@Description: 按mysql information_schema.columns 中 EXTRA 的格式设置自增、表达式默认值、更新时的值及生成列，
+//	例：auto_increment、DEFAULT_GENERATED on update CURRENT_TIMESTAMP(3)、VIRTUAL GENERATED
+//	@Auth shigx 2024-09-30 10:26:14
+//	@param extra
+func (c *TableColumn) SetExtra(extra string) {
+	lower := strings.ToLower(extra)
+	c.AutoIncrement = strings.Contains(lower, "auto_increment")
+	c.DefaultExpr = strings.Contains(lower, "default_generated")
+	c.OnUpdate = ""
+	if k := strings.Index(lower, "on update "); k >= 0 {
+		if fields := strings.Fields(extra[k+len("on update "):]); len(fields) > 0 {
+			c.OnUpdate = fields[0]
+		}
+	}
+	switch {
+	case strings.Contains(lower, "virtual generated"):
+		c.Generated = GeneratedVirtual
+	case strings.Contains(lower, "stored generated"):
+		c.Generated = GeneratedStored
+	default:
+		c.Generated = ""
+	}
+}
+
+// CurrentTimestamp
+//
+//	@Description: 判断默认值是否为当前时间，NOW()、LOCALTIMESTAMP 等同义词与information_schema一致统一为 CURRENT_TIMESTAMP，保留精度
+//	@Auth shigx 2024-06-03 10:12:30
+//	@param value 默认值，例：CURRENT_TIMESTAMP、CURRENT_TIMESTAMP(3)、now()、LOCALTIMESTAMP
+//	@return string
+//	@return bool
+func CurrentTimestamp(value string) (string, bool) {
+	upper := strings.ToUpper(value)
+	for _, synonym := range []string{"CURRENT_TIMESTAMP", "LOCALTIMESTAMP", "LOCALTIME", "NOW"} {
+		rest, ok := strings.CutPrefix(upper, synonym)
+		if !ok || (rest != "" && rest[0] != '(') || (rest == "" && synonym == "NOW") {
+			continue
+		}
+		return "CURRENT_TIMESTAMP" + strings.TrimPrefix(rest, "()"), true
+	}
+
+	return value, false
+}
+
+// TableIndex @Description 表索引信息定义
+// @Auth shigx
+// @Date 2024-06-26 11:18:40
+type TableIndex struct {
+	Name    string        `json:"name" yaml:"name"`                           // 索引名，主键为PRIMARY
+	Unique  bool          `json:"unique" yaml:"unique"`                       // 是否唯一索引
+	Type    string        `json:"type" yaml:"type"`                           // 索引类型：BTREE、HASH、FULLTEXT、SPATIAL、GIN 等
+	Columns []IndexColumn `json:"columns" yaml:"columns"`                     // 索引字段，按索引中的顺序
+	Comment string        `json:"comment,omitempty" yaml:"comment,omitempty"` // 索引备注
+}
+
+// IndexColumn @Description 索引字段信息定义
+// @Auth shigx
+// @Date 2024-06-26 11:18:40
+type IndexColumn struct {
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`             // 字段名，表达式索引为空
+	Length     int64  `json:"length,omitempty" yaml:"length,omitempty"`         // 前缀索引长度，0表示完整字段
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"` // 表达式索引的表达式，例：lower(name)
+}
+
+// String 返回索引字段描述，前缀索引带长度，表达式加括号，例：name(10)、(lower(name))
+func (c IndexColumn) String() string {
+	switch {
+	case c.Expression != "":
+		return "(" + c.Expression + ")"
+	case c.Length > 0:
+		return c.Name + "(" + strconv.FormatInt(c.Length, 10) + ")"
+	}
+
+	return c.Name
+}
+
+// IsPrimary 是否主键
+func (i TableIndex) IsPrimary() bool {
+	return i.Name == PrimaryKey
+}
+
+// ColumnNames 返回索引字段名列表，不含表达式
+func (i TableIndex) ColumnNames() []string {
+	names := make([]string, 0, len(i.Columns))
+	for _, column := range i.Columns {
+		if column.Name != "" {
+			names = append(names, column.Name)
+		}
+	}
+
+	return names
+}
+
+// ForeignKey @Description 外键信息定义
+// @Auth shigx
+// @Date 2024-06-28 15:02:33
+type ForeignKey struct {
+	Name              string   `json:"name" yaml:"name"`                             // 约束名
+	Table             string   `json:"table" yaml:"table"`                           // 外键所在表
+	Columns           []string `json:"columns" yaml:"columns"`                       // 外键字段
+	ReferencedTable   string   `json:"referenced_table" yaml:"referenced_table"`     // 关联表
+	ReferencedColumns []string `json:"referenced_columns" yaml:"referenced_columns"` // 关联字段
+	OnUpdate          string   `json:"on_update" yaml:"on_update"`                   // 更新规则：CASCADE、SET NULL、RESTRICT、NO ACTION 等
+	OnDelete          string   `json:"on_delete" yaml:"on_delete"`                   // 删除规则
+}
+
+// SortIndexes 主键排在最前，其他索引按索引名排序
+func SortIndexes(indexes []TableIndex) {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if indexes[i].IsPrimary() != indexes[j].IsPrimary() {
+			return indexes[i].IsPrimary()
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+}
+
+// ColumnKeys
+//
+//	@Description: 按索引推断字段键，与mysql information_schema一致：主键字段为PRI，单字段唯一索引为UNI，其他索引首字段为MUL
+//	@Auth shigx 2024-06-26 11:18:40
+//	@param indexes
+//	@return map[string]string 小写字段名 => 字段键
+func ColumnKeys(indexes []TableIndex) map[string]string {
+	priority := map[string]int{"": 0, "MUL": 1, "UNI": 2, "PRI": 3}
+	keys := make(map[string]string)
+	set := func(column string, key string) {
+		if column == "" {
+			return
+		}
+		column = strings.ToLower(column)
+		if priority[key] > priority[keys[column]] {
+			keys[column] = key
+		}
+	}
+	for _, index := range indexes {
+		switch {
+		case index.IsPrimary():
+			for _, column := range index.Columns {
+				set(column.Name, "PRI")
+			}
+		case index.Unique && len(index.Columns) == 1:
+			set(index.Columns[0].Name, "UNI")
+		case len(index.Columns) > 0:
+			set(index.Columns[0].Name, "MUL")
+		}
+	}
+
+	return keys
+}
+
+// SetColumnKeys 按索引设置字段键，用于不提供字段键的数据库及建表语句
+func SetColumnKeys(columns []TableColumn, indexes []TableIndex) {
+	keys := ColumnKeys(indexes)
+	for k := range columns {
+		columns[k].ColumnKey = sql.NullString{String: keys[strings.ToLower(columns[k].ColumnName)], Valid: true}
+	}
+}
@@ -0,0 +1,38 @@
+package schema
+
+import "testing"
+
+func TestTableColumnSetExtra(t *testing.T) {
+	tests := []struct {
+		extra string
+		want  TableColumn
+	}{
+		{"", TableColumn{}},
+		{"auto_increment", TableColumn{AutoIncrement: true}},
+		{"DEFAULT_GENERATED", TableColumn{DefaultExpr: true}},
+		{"DEFAULT_GENERATED on update CURRENT_TIMESTAMP(3)", TableColumn{DefaultExpr: true, OnUpdate: "CURRENT_TIMESTAMP(3)"}},
+		{"VIRTUAL GENERATED", TableColumn{Generated: GeneratedVirtual}},
+		{"STORED GENERATED", TableColumn{Generated: GeneratedStored}},
+		// on update 后没有值时忽略
+		{"on update ", TableColumn{}},
+		{"on update", TableColumn{}},
+	}
+	for _, tt := range tests {
+		column := TableColumn{AutoIncrement: true, OnUpdate: "NOW()", Generated: GeneratedStored}
+		column.SetExtra(tt.extra)
+		if column != tt.want {
+			t.Errorf("SetExtra(%q) = %+v, want %+v", tt.extra, column, tt.want)
+		}
+	}
+}
+
+func TestTableColumnExtra(t *testing.T) {
+	column := TableColumn{AutoIncrement: true, OnUpdate: "CURRENT_TIMESTAMP", Generated: GeneratedStored}
+	if got, want := column.Extra(), "auto_increment on update CURRENT_TIMESTAMP STORED GENERATED"; got != want {
+		t.Errorf("Extra() = %q, want %q", got, want)
+	}
+	column.SetExtra(column.Extra())
+	if !column.AutoIncrement || column.OnUpdate != "CURRENT_TIMESTAMP" || column.Generated != GeneratedStored {
+		t.Errorf("SetExtra(Extra()) = %+v, want the same fields", column)
+	}
+}
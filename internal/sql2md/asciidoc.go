@@ -0,0 +1,133 @@
+// Package sql2md
+// @Description: asciidoc格式文档
+// @Auth shigx 2024-07-15 10:40:05
+package sql2md
+
+import (
+	"fmt"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// asciidocRenderer 目录文件为 index.adoc，表间链接使用 xref
+type asciidocRenderer struct{}
+
+func (asciidocRenderer) Ext() string {
+	return "adoc"
+}
+
+func (asciidocRenderer) IndexFile() string {
+	return "index.adoc"
+}
+
+func (asciidocRenderer) Table(dbName string, table *schema.Table) (string, error) {
+	content := fmt.Sprintf("== %s\n\n", strings.TrimPrefix(dbName+"."+table.Name, "."))
+	if table.Comment != "" {
+		content += adocCell(table.Comment) + "\n\n"
+	}
+
+	rows := make([][]string, 0, len(table.Columns))
+	for _, row := range table.Columns {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", row.OrdinalPosition),
+			row.ColumnName,
+			row.ColumnType,
+			row.ColumnKey.String,
+			row.IsNullable,
+			row.Extra(),
+			row.ColumnDefault.String,
+			row.ColumnComment.String,
+		})
+	}
+	content += adocTable("1,3,3,1,1,2,2,4", []string{"序号", "字段名", "类型", "键", "为空", "额外", "默认值", "描述"}, rows)
+
+	if len(table.Indexes) > 0 {
+		rows = make([][]string, 0, len(table.Indexes))
+		for _, index := range table.Indexes {
+			rows = append(rows, []string{index.Name, strings.Join(IndexColumns(index), ", "), indexUnique(index), index.Type, index.Comment})
+		}
+		content += "\n=== 索引\n\n" + adocTable("3,4,1,2,3", []string{"索引名", "字段", "唯一", "类型", "描述"}, rows)
+	}
+	if len(table.ForeignKeys) > 0 {
+		rows = make([][]string, 0, len(table.ForeignKeys))
+		for _, fk := range table.ForeignKeys {
+			rows = append(rows, []string{
+				fk.Name,
+				strings.Join(fk.Columns, ", "),
+				adocXref(fk.ReferencedTable),
+				strings.Join(fk.ReferencedColumns, ", "),
+				fk.OnUpdate,
+				fk.OnDelete,
+			})
+		}
+		content += "\n=== 外键\n\n" + adocTable("3,3,3,3,2,2", []string{"约束名", "字段", "关联表", "关联字段", "更新", "删除"}, rows)
+	}
+	if len(table.ReferencedBy) > 0 {
+		rows = make([][]string, 0, len(table.ReferencedBy))
+		for _, fk := range table.ReferencedBy {
+			rows = append(rows, []string{
+				adocXref(fk.Table),
+				strings.Join(fk.Columns, ", "),
+				fk.Name,
+				strings.Join(fk.ReferencedColumns, ", "),
+			})
+		}
+		content += "\n=== 被引用\n\n" + adocTable("3,3,3,3", []string{"引用表", "字段", "约束名", "关联字段"}, rows)
+	}
+
+	return content, nil
+}
+
+func (asciidocRenderer) Index(dbName string, tables []*schema.Table) (string, error) {
+	content := "= 数据库文档\n\n"
+	if dbName != "" {
+		content = fmt.Sprintf("= %s 数据库文档\n\n", dbName)
+	}
+	content += fmt.Sprintf("共 %d 个表，行数为统计信息中的估算值\n\n", len(tables))
+
+	rows := make([][]string, 0, len(tables))
+	for k, table := range tables {
+		links := make([]string, 0)
+		for _, name := range referencedTables(table) {
+			links = append(links, adocXref(name))
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", k+1),
+			adocXref(table.Name),
+			table.Comment,
+			rowsText(table),
+			strings.Join(links, ", "),
+		})
+	}
+	content += adocTable("1,3,4,2,3", []string{"序号", "表名", "描述", "约行数", "关联表"}, rows)
+
+	return content, nil
+}
+
+// adocTable 生成asciidoc表格，xref链接单元格不转义
+func adocTable(cols string, header []string, rows [][]string) string {
+	content := fmt.Sprintf("[cols=\"%s\",options=\"header\"]\n|===\n", cols)
+	content += "|" + strings.Join(header, " |") + "\n"
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			if !strings.HasPrefix(cell, "xref:") {
+				cell = adocCell(cell)
+			}
+			cells = append(cells, cell)
+		}
+		content += "\n|" + strings.Join(cells, "\n|") + "\n"
+	}
+
+	return content + "|===\n"
+}
+
+// adocXref 生成指向同目录表文档的链接
+func adocXref(table string) string {
+	return fmt.Sprintf("xref:%s.adoc[%s]", table, adocCell(table))
+}
+
+// adocCell 转义单元格中的竖线并去除换行
+func adocCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
@@ -0,0 +1,74 @@
+// Package sql2md
+// @Description: csv、tsv格式文档，适用于电子表格
+// @Auth shigx 2024-07-15 10:40:05
+package sql2md
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// utf8Bom 文件头，电子表格软件据此识别utf-8编码
+const utf8Bom = "\ufeff"
+
+// csvRenderer 单表文件为字段列表，目录文件为表列表
+type csvRenderer struct {
+	comma rune   // 分隔符
+	ext   string // 文件扩展名
+}
+
+func (r csvRenderer) Ext() string {
+	return r.ext
+}
+
+func (r csvRenderer) IndexFile() string {
+	return "tables." + r.ext
+}
+
+func (r csvRenderer) Table(dbName string, table *schema.Table) (string, error) {
+	records := [][]string{{"序号", "字段名", "类型", "键", "为空", "额外", "默认值", "描述"}}
+	for _, row := range table.Columns {
+		records = append(records, []string{
+			fmt.Sprintf("%d", row.OrdinalPosition),
+			row.ColumnName,
+			row.ColumnType,
+			row.ColumnKey.String,
+			row.IsNullable,
+			row.Extra(),
+			row.ColumnDefault.String,
+			row.ColumnComment.String,
+		})
+	}
+
+	return r.write(records)
+}
+
+func (r csvRenderer) Index(dbName string, tables []*schema.Table) (string, error) {
+	records := [][]string{{"序号", "表名", "描述", "约行数", "关联表"}}
+	for k, table := range tables {
+		records = append(records, []string{
+			fmt.Sprintf("%d", k+1),
+			table.Name,
+			table.Comment,
+			rowsText(table),
+			strings.Join(referencedTables(table), ","),
+		})
+	}
+
+	return r.write(records)
+}
+
+// write 按分隔符输出全部记录
+func (r csvRenderer) write(records [][]string) (string, error) {
+	buf := bytes.NewBufferString(utf8Bom)
+	w := csv.NewWriter(buf)
+	w.Comma = r.comma
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,130 @@
+// Package sql2md
+// @Description: html格式文档，样式及搜索脚本内嵌，不依赖外部资源
+// @Auth shigx 2024-07-15 10:40:05
+package sql2md
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"html/template"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// htmlPage html模版数据
+type htmlPage struct {
+	DbName string
+	Tables []*schema.Table
+	Index  bool // 是否目录页，目录页包含全部表及搜索框
+}
+
+const htmlTpl = `{{define "table"}}<section class="table" id="{{.Name}}">
+<h2><a href="{{.Name}}.html">{{.Name}}</a></h2>
+{{if .Comment}}<p>{{.Comment}}</p>
+{{end}}<table>
+<thead><tr><th>序号</th><th>字段名</th><th>类型</th><th>键</th><th>为空</th><th>额外</th><th>默认值</th><th>描述</th></tr></thead>
+<tbody>
+{{range .Columns}}<tr><td>{{.OrdinalPosition}}</td><td>{{.ColumnName}}</td><td>{{.ColumnType}}</td><td>{{.ColumnKey.String}}</td><td>{{.IsNullable}}</td><td>{{.Extra}}</td><td>{{.ColumnDefault.String}}</td><td>{{.ColumnComment.String}}</td></tr>
+{{end}}</tbody>
+</table>
+{{if .Indexes}}<h3>索引</h3>
+<table>
+<thead><tr><th>索引名</th><th>字段</th><th>唯一</th><th>类型</th><th>描述</th></tr></thead>
+<tbody>
+{{range .Indexes}}<tr><td>{{.Name}}</td><td>{{indexColumns .}}</td><td>{{unique .}}</td><td>{{.Type}}</td><td>{{.Comment}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{if .ForeignKeys}}<h3>外键</h3>
+<table>
+<thead><tr><th>约束名</th><th>字段</th><th>关联表</th><th>关联字段</th><th>更新</th><th>删除</th></tr></thead>
+<tbody>
+{{range .ForeignKeys}}<tr><td>{{.Name}}</td><td>{{join .Columns ", "}}</td><td><a href="{{.ReferencedTable}}.html">{{.ReferencedTable}}</a></td><td>{{join .ReferencedColumns ", "}}</td><td>{{.OnUpdate}}</td><td>{{.OnDelete}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{if .ReferencedBy}}<h3>被引用</h3>
+<table>
+<thead><tr><th>引用表</th><th>字段</th><th>约束名</th><th>关联字段</th></tr></thead>
+<tbody>
+{{range .ReferencedBy}}<tr><td><a href="{{.Table}}.html">{{.Table}}</a></td><td>{{join .Columns ", "}}</td><td>{{.Name}}</td><td>{{join .ReferencedColumns ", "}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}</section>
+{{end}}<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Index}}{{.DbName}} 数据库文档{{else}}{{(index .Tables 0).Name}}{{end}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI","PingFang SC","Microsoft YaHei",sans-serif;margin:2em;color:#24292f}
+table{border-collapse:collapse;margin:.5em 0 1.5em}
+th,td{border:1px solid #d0d7de;padding:4px 10px;text-align:left;vertical-align:top}
+th{background:#f6f8fa}
+a{color:#0969da;text-decoration:none}
+#search{padding:6px 10px;width:320px;margin-bottom:1em}
+.hidden{display:none}
+</style>
+</head>
+<body>
+{{if .Index}}<h1>{{.DbName}} 数据库文档</h1>
+<p>共 {{len .Tables}} 个表，行数为统计信息中的估算值</p>
+<input id="search" type="search" placeholder="搜索表名、字段名、描述">
+<table id="tables">
+<thead><tr><th>序号</th><th>表名</th><th>描述</th><th>约行数</th><th>关联表</th></tr></thead>
+<tbody>
+{{range $k, $table := .Tables}}<tr data-table="{{$table.Name}}"><td>{{inc $k}}</td><td><a href="#{{$table.Name}}">{{$table.Name}}</a></td><td>{{$table.Comment}}</td><td>{{rows $table}}</td><td>{{range $i, $name := referenced $table}}{{if $i}}, {{end}}<a href="#{{$name}}">{{$name}}</a>{{end}}</td></tr>
+{{end}}</tbody>
+</table>
+{{range .Tables}}{{template "table" .}}{{end}}<script>
+document.getElementById("search").addEventListener("input", function () {
+  var keyword = this.value.trim().toLowerCase();
+  document.querySelectorAll("section.table").forEach(function (section) {
+    var matched = keyword === "" || section.textContent.toLowerCase().indexOf(keyword) >= 0;
+    section.classList.toggle("hidden", !matched);
+    document.querySelector('#tables tr[data-table="' + section.id + '"]').classList.toggle("hidden", !matched);
+  });
+});
+</script>
+{{else}}<p><a href="index.html">返回目录</a></p>
+{{range .Tables}}{{template "table" .}}{{end}}{{end}}</body>
+</html>
+`
+
+// htmlTemplate html模版，表间链接指向同目录下的 <表名>.html
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"indexColumns": func(index schema.TableIndex) string { return strings.Join(IndexColumns(index), ", ") },
+	"unique":       indexUnique,
+	"join":         strings.Join,
+	"rows":         rowsText,
+	"referenced":   referencedTables,
+	"inc":          func(k int) int { return k + 1 },
+}).Parse(htmlTpl))
+
+// htmlRenderer 单表页面及包含全部表、支持搜索的目录页 index.html
+type htmlRenderer struct{}
+
+func (htmlRenderer) Ext() string {
+	return "html"
+}
+
+func (htmlRenderer) IndexFile() string {
+	return "index.html"
+}
+
+func (htmlRenderer) Table(dbName string, table *schema.Table) (string, error) {
+	return renderHTML(&htmlPage{DbName: dbName, Tables: []*schema.Table{table}})
+}
+
+func (htmlRenderer) Index(dbName string, tables []*schema.Table) (string, error) {
+	return renderHTML(&htmlPage{DbName: dbName, Tables: tables, Index: true})
+}
+
+// renderHTML 执行html模版
+func renderHTML(page *htmlPage) (string, error) {
+	buf := bytes.NewBufferString("")
+	if err := htmlTemplate.Execute(buf, page); err != nil {
+		return "", errors.WithMessage(err, "template data err")
+	}
+
+	return buf.String(), nil
+}
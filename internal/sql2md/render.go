@@ -0,0 +1,143 @@
+// Package sql2md
+// @Description: 文档输出格式，每种格式生成单表文件及全部表的目录文件
+// @Auth shigx 2024-07-15 10:40:05
+package sql2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 输出格式
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatAsciiDoc = "asciidoc"
+)
+
+// Formats 支持的输出格式
+var Formats = []string{FormatMarkdown, FormatHTML, FormatJSON, FormatCSV, FormatTSV, FormatAsciiDoc}
+
+// Renderer @Description 文档生成器，单表文件名为 <表名>.<Ext>，表间链接指向同目录下的文件
+// @Auth shigx
+// @Date 2024-07-15 10:40:05
+type Renderer interface {
+	// Ext 单表文件扩展名
+	Ext() string
+	// IndexFile 目录文件名
+	IndexFile() string
+	// Table 生成单表文档
+	Table(dbName string, table *schema.Table) (string, error)
+	// Index 生成全部表的目录文档，按传入顺序输出
+	Index(dbName string, tables []*schema.Table) (string, error)
+}
+
+// NewRenderer
+//
+//	@Description: 按格式返回文档生成器
+//	@Auth shigx 2024-07-15 10:40:05
+//	@param format
+//	@param driver 表结构来源的数据库驱动，写入json格式的 schema.json
+//	@return Renderer
+//	@return error
+func NewRenderer(format string, driver string) (Renderer, error) {
+	switch format {
+	case FormatMarkdown, "md", "":
+		return markdownRenderer{}, nil
+	case FormatHTML:
+		return htmlRenderer{}, nil
+	case FormatJSON:
+		return jsonRenderer{driver: driver}, nil
+	case FormatCSV:
+		return csvRenderer{comma: ',', ext: "csv"}, nil
+	case FormatTSV:
+		return csvRenderer{comma: '\t', ext: "tsv"}, nil
+	case FormatAsciiDoc, "adoc":
+		return asciidocRenderer{}, nil
+	}
+
+	return nil, fmt.Errorf("不支持的文档格式：%s，可选值：%s", format, strings.Join(Formats, "、"))
+}
+
+// markdownRenderer markdown文档，目录文件为 README.md
+type markdownRenderer struct{}
+
+func (markdownRenderer) Ext() string {
+	return "md"
+}
+
+func (markdownRenderer) IndexFile() string {
+	return "README.md"
+}
+
+func (markdownRenderer) Table(dbName string, table *schema.Table) (string, error) {
+	return GetMdContent(table, dbName), nil
+}
+
+func (markdownRenderer) Index(dbName string, tables []*schema.Table) (string, error) {
+	return GetReadmeContent(dbName, tables, "md"), nil
+}
+
+// jsonRenderer json格式表结构，目录文件为包含全部表的 schema.json，可作为快照读取
+type jsonRenderer struct {
+	driver string
+}
+
+func (jsonRenderer) Ext() string {
+	return "json"
+}
+
+func (jsonRenderer) IndexFile() string {
+	return "schema.json"
+}
+
+func (jsonRenderer) Table(dbName string, table *schema.Table) (string, error) {
+	return marshalJSON(table)
+}
+
+func (r jsonRenderer) Index(dbName string, tables []*schema.Table) (string, error) {
+	snapshot := schema.Schema{
+		Version:  schema.SchemaVersion,
+		Driver:   r.driver,
+		Database: dbName,
+		Tables:   make([]schema.Table, 0, len(tables)),
+	}
+	for _, table := range tables {
+		snapshot.Tables = append(snapshot.Tables, *table)
+	}
+
+	return marshalJSON(snapshot)
+}
+
+// marshalJSON 生成带缩进的json，以换行结尾
+func marshalJSON(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(data) + "\n", nil
+}
+
+// rowsText 返回估算行数，未知时为 -
+func rowsText(table *schema.Table) string {
+	if !table.Rows.Valid {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d", table.Rows.Int64)
+}
+
+// indexUnique 返回索引是否唯一的描述
+func indexUnique(index schema.TableIndex) string {
+	if index.Unique {
+		return "YES"
+	}
+
+	return "NO"
+}
@@ -0,0 +1,33 @@
+package sql2md
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"tool-cli/internal/schema"
+)
+
+func TestJSONIndexIsSnapshot(t *testing.T) {
+	renderer, err := NewRenderer(FormatJSON, schema.DriverPostgres)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	table := &schema.Table{Name: "user", Columns: []schema.TableColumn{{OrdinalPosition: 1, ColumnName: "id", ColumnType: "int8", DataType: "int8", IsNullable: "NO"}}}
+	content, err := renderer.Index("shop", []*schema.Table{table})
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	// schema.json 可作为快照读取，保留来源驱动
+	path := filepath.Join(t.TempDir(), renderer.IndexFile())
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := schema.ReadSnapshot(path)
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	if snapshot.Driver != schema.DriverPostgres || snapshot.Database != "shop" || len(snapshot.Tables) != 1 {
+		t.Errorf("ReadSnapshot() driver = %q, database = %q, tables = %d, want postgres, shop, 1", snapshot.Driver, snapshot.Database, len(snapshot.Tables))
+	}
+}
@@ -5,8 +5,9 @@ package sql2md
 
 import (
 	"fmt"
+	"sort"
 	"strings"
-	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
 )
 
 // GetMdContent
@@ -15,25 +16,173 @@ import (
 // @Date 2024-05-14 17:48:21
 // @param
 // @return
-func GetMdContent(columns []mysql.TableColumn, dbName string, tableName string, tableComment string) string {
-	mdContent := fmt.Sprintf("#### %s.%s \n", dbName, tableName)
-	if tableComment != "" {
-		mdContent += tableComment + "\n"
+func GetMdContent(table *schema.Table, dbName string) string {
+	mdContent := fmt.Sprintf("#### %s.%s \n", dbName, table.Name)
+	if table.Comment != "" {
+		mdContent += table.Comment + "\n"
 	}
 	mdContent += "\n" +
 		"|  序号 |           字段名 |             类型 |    键 |   为空 |                 额外 |      默认值 |                 描述 |\n" +
 		"| :---: | :-------------: | :-------------: | :---: | :---: | :------------------: | :--------: | :------------------: |\n"
-	for _, row := range columns {
+	for _, row := range table.Columns {
 		mdContent += fmt.Sprintf("| %5d | %15s | %15s | %5s | %5s | %20s | %10s | %20s |\n",
 			row.OrdinalPosition,
 			row.ColumnName,
 			row.ColumnType,
 			row.ColumnKey.String,
 			row.IsNullable,
-			row.Extra.String,
+			row.Extra(),
 			row.ColumnDefault.String,
-			strings.ReplaceAll(strings.ReplaceAll(row.ColumnComment.String, "|", "\\|"), "\n", ""),
+			escapeCell(row.ColumnComment.String),
 		)
 	}
+	mdContent += getIndexContent(table.Indexes)
+	mdContent += getForeignKeyContent(table.ForeignKeys)
+	mdContent += getReferencedByContent(table.ReferencedBy)
+
+	return mdContent
+}
+
+// getIndexContent
+//
+//	@Description: 生成索引信息，没有索引时返回空
+//	@Auth shigx 2024-06-26 11:18:40
+//	@param indexes
+//	@return string
+func getIndexContent(indexes []schema.TableIndex) string {
+	if len(indexes) == 0 {
+		return ""
+	}
+
+	mdContent := "\n##### 索引\n\n" +
+		"|           索引名 |                           字段 |  唯一 |       类型 |                 描述 |\n" +
+		"| :-------------: | :--------------------------: | :---: | :--------: | :------------------: |\n"
+	for _, index := range indexes {
+		mdContent += fmt.Sprintf("| %15s | %28s | %5s | %10s | %20s |\n",
+			index.Name,
+			strings.Join(IndexColumns(index), ", "),
+			indexUnique(index),
+			index.Type,
+			escapeCell(index.Comment),
+		)
+	}
+
+	return mdContent
+}
+
+// getForeignKeyContent
+//
+//	@Description: 生成外键信息，关联表链接到同目录下的文档，没有外键时返回空
+//	@Auth shigx 2024-06-28 16:20:12
+//	@param foreignKeys
+//	@return string
+func getForeignKeyContent(foreignKeys []schema.ForeignKey) string {
+	if len(foreignKeys) == 0 {
+		return ""
+	}
+
+	mdContent := "\n##### 外键\n\n" +
+		"|           约束名 |                 字段 |                 关联表 |             关联字段 |        更新 |        删除 |\n" +
+		"| :-------------: | :------------------: | :-------------------: | :------------------: | :--------: | :--------: |\n"
+	for _, fk := range foreignKeys {
+		mdContent += fmt.Sprintf("| %15s | %20s | %21s | %20s | %10s | %10s |\n",
+			fk.Name,
+			strings.Join(fk.Columns, ", "),
+			fmt.Sprintf("[%s](%s.md)", fk.ReferencedTable, fk.ReferencedTable),
+			strings.Join(fk.ReferencedColumns, ", "),
+			fk.OnUpdate,
+			fk.OnDelete,
+		)
+	}
+
+	return mdContent
+}
+
+// getReferencedByContent
+//
+//	@Description: 生成被其他表引用的外键信息，引用表链接到同目录下的文档，没有时返回空
+//	@Auth shigx 2024-07-02 10:25:16
+//	@param foreignKeys
+//	@return string
+func getReferencedByContent(foreignKeys []schema.ForeignKey) string {
+	if len(foreignKeys) == 0 {
+		return ""
+	}
+
+	mdContent := "\n##### 被引用\n\n" +
+		"|                  引用表 |                 字段 |           约束名 |             关联字段 |\n" +
+		"| :-------------------: | :------------------: | :-------------: | :------------------: |\n"
+	for _, fk := range foreignKeys {
+		mdContent += fmt.Sprintf("| %21s | %20s | %15s | %20s |\n",
+			fmt.Sprintf("[%s](%s.md)", fk.Table, fk.Table),
+			strings.Join(fk.Columns, ", "),
+			fk.Name,
+			strings.Join(fk.ReferencedColumns, ", "),
+		)
+	}
+
+	return mdContent
+}
+
+// GetReadmeContent
+//
+//	@Description: 生成文档目录，列出表名（链接到表文档）、备注、估算行数及关联的表，按传入顺序输出
+//	@Auth shigx 2024-07-02 10:25:16
+//	@param dbName
+//	@param tables
+//	@param ext 表文档扩展名，链接指向同目录下的 <表名>.<ext>
+//	@return string
+func GetReadmeContent(dbName string, tables []*schema.Table, ext string) string {
+	mdContent := "#### 数据库文档\n"
+	if dbName != "" {
+		mdContent = fmt.Sprintf("#### %s 数据库文档\n", dbName)
+	}
+	mdContent += fmt.Sprintf("\n共 %d 个表，行数为统计信息中的估算值\n\n", len(tables)) +
+		"|  序号 |                         表名 |                 描述 |       约行数 |                     关联表 |\n" +
+		"| :---: | :-------------------------: | :------------------: | :--------: | :-----------------------: |\n"
+	for k, table := range tables {
+		links := make([]string, 0)
+		for _, name := range referencedTables(table) {
+			links = append(links, fmt.Sprintf("[%s](%s.%s)", name, name, ext))
+		}
+		mdContent += fmt.Sprintf("| %5d | %27s | %20s | %10s | %25s |\n",
+			k+1,
+			fmt.Sprintf("[%s](%s.%s)", table.Name, table.Name, ext),
+			escapeCell(table.Comment),
+			rowsText(table),
+			strings.Join(links, ", "),
+		)
+	}
+
 	return mdContent
 }
+
+// referencedTables 返回表外键关联的表名，去重并按表名排序
+func referencedTables(table *schema.Table) []string {
+	names := make([]string, 0, len(table.ForeignKeys))
+	seen := make(map[string]bool)
+	for _, fk := range table.ForeignKeys {
+		if !seen[fk.ReferencedTable] {
+			seen[fk.ReferencedTable] = true
+			names = append(names, fk.ReferencedTable)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// IndexColumns 返回索引字段描述，前缀索引带长度，表达式加括号，例：name(10)、(lower(name))
+func IndexColumns(index schema.TableIndex) []string {
+	columns := make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		columns = append(columns, column.String())
+	}
+
+	return columns
+}
+
+// escapeCell 转义表格单元格中的竖线并去除换行
+func escapeCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", "")
+}
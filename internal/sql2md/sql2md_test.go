@@ -0,0 +1,43 @@
+package sql2md
+
+import (
+	"strings"
+	"testing"
+	"tool-cli/internal/mysql"
+)
+
+func TestGetMdContentIndexes(t *testing.T) {
+	tables, err := mysql.ParseDdl(`
+CREATE TABLE member (
+  tenant_id int NOT NULL,
+  id bigint NOT NULL,
+  email varchar(128) NOT NULL,
+  nickname varchar(64) NOT NULL,
+  PRIMARY KEY (tenant_id, id),
+  UNIQUE KEY uk_tenant_email (tenant_id, email),
+  KEY idx_nickname (nickname(16)) COMMENT '昵称|前缀',
+  KEY idx_lower_email ((lower(email)))
+);`)
+	if err != nil {
+		t.Fatalf("ParseDdl() error = %v", err)
+	}
+	content := GetMdContent(&tables[0], "shop")
+
+	// 主键排在最前，其他索引按索引名排序
+	want := "\n##### 索引\n\n" +
+		"|           索引名 |                           字段 |  唯一 |       类型 |                 描述 |\n" +
+		"| :-------------: | :--------------------------: | :---: | :--------: | :------------------: |\n" +
+		"|         PRIMARY |                tenant_id, id |   YES |      BTREE |                      |\n" +
+		"| idx_lower_email |               (lower(email)) |    NO |      BTREE |                      |\n" +
+		"|    idx_nickname |                 nickname(16) |    NO |      BTREE |               昵称\\|前缀 |\n" +
+		"| uk_tenant_email |             tenant_id, email |   YES |      BTREE |                      |\n"
+	if !strings.Contains(content, want) {
+		t.Errorf("GetMdContent() indexes section mismatch\n--- got\n%s\n--- want\n%s", content, want)
+	}
+
+	// 没有索引时不输出索引部分
+	tables[0].Indexes = nil
+	if content := GetMdContent(&tables[0], "shop"); strings.Contains(content, "##### 索引") {
+		t.Errorf("GetMdContent() without indexes = %q, want no indexes section", content)
+	}
+}
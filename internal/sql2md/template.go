@@ -0,0 +1,42 @@
+// Package sql2md
+// @Description: 自定义模版生成文档
+// @Auth shigx 2024-06-24 10:30:52
+package sql2md
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/tmpl"
+)
+
+// Doc @Description 模版数据，自定义模版可使用全部字段及 tmpl.FuncMap 中的函数
+// @Auth shigx
+// @Date 2024-06-24 10:30:52
+type Doc struct {
+	DbName string        // 数据库名
+	Table  *schema.Table // 表信息，含表名 Name、备注 Comment 及字段列表 Columns
+}
+
+// GetMdContentByTemplate
+//
+//	@Description: 使用自定义模版文件生成文档
+//	@Auth shigx 2024-06-24 10:30:52
+//	@param file 模版文件
+//	@param dbName
+//	@param table
+//	@return string
+//	@return error
+func GetMdContentByTemplate(file string, dbName string, table *schema.Table) (string, error) {
+	t, err := tmpl.Parse("sql2md", file, "")
+	if err != nil {
+		return "", err
+	}
+
+	buf := bytes.NewBufferString("")
+	if err = t.Execute(buf, &Doc{DbName: dbName, Table: table}); err != nil {
+		return "", errors.WithMessage(err, "template data err")
+	}
+
+	return buf.String(), nil
+}
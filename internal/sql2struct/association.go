@@ -0,0 +1,138 @@
+// Package sql2struct
+// @Title 关联字段生成
+// @Description 按外键生成 BelongsTo、HasMany 关联字段
+// @Author shigx 2024-06-28 15:02:33
+package sql2struct
+
+import (
+	"github.com/jinzhu/inflection"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 关联类型
+const (
+	RelationBelongsTo = "belongs_to"
+	RelationHasMany   = "has_many"
+)
+
+// getAssociationFields
+//
+//	@Description: 生成关联字段，本表外键生成 BelongsTo，其他表引用本表的外键生成 HasMany，关联的表需生成在同一个包中
+//	@Auth shigx 2024-06-28 15:02:33
+//	@param table
+//	@param config
+//	@param namer
+//	@param fieldNames 已使用的字段名
+//	@param columnFields 本表字段名 => go字段名
+//	@return []Field
+func getAssociationFields(table *schema.Table, config *Config, namer *Namer, fieldNames map[string]bool, columnFields map[string]string) []Field {
+	// 自关联时外键两端都是本表字段
+	fieldsOf := func(tableName string) map[string]string {
+		if tableName == table.Name {
+			return columnFields
+		}
+		return nil
+	}
+	fields := make([]Field, 0)
+	for _, fk := range table.ForeignKeys {
+		name := namer.StructName(fk.ReferencedTable, config)
+		// 单字段外键使用去除 id 后缀的字段名，例：creator_id => Creator
+		if len(fk.Columns) == 1 {
+			if trimmed := trimIdSuffix(fk.Columns[0]); trimmed != "" {
+				name = namer.FieldName(trimmed)
+			}
+		}
+		name = uniqueName(fieldNames, name)
+		foreignKey, references := fieldList(fk.Columns, columnFields, namer), fieldList(fk.ReferencedColumns, fieldsOf(fk.ReferencedTable), namer)
+		fields = append(fields, Field{
+			Name:     name,
+			Type:     "*" + namer.StructName(fk.ReferencedTable, config),
+			Tag:      getAssociationTag(name, RelationBelongsTo, fk, foreignKey, references, config),
+			Relation: RelationBelongsTo,
+		})
+	}
+	for _, fk := range table.ReferencedBy {
+		name := uniqueName(fieldNames, inflection.Plural(namer.StructName(fk.Table, config)))
+		foreignKey, references := fieldList(fk.Columns, fieldsOf(fk.Table), namer), fieldList(fk.ReferencedColumns, columnFields, namer)
+		fields = append(fields, Field{
+			Name:     name,
+			Type:     "[]" + namer.StructName(fk.Table, config),
+			Tag:      getAssociationTag(name, RelationHasMany, fk, foreignKey, references, config),
+			Relation: RelationHasMany,
+		})
+	}
+
+	return fields
+}
+
+// trimIdSuffix 去除字段名的id后缀，例：user_id => user，userId => user，不以id结尾返回空
+func trimIdSuffix(column string) string {
+	switch {
+	case len(column) > 3 && strings.HasSuffix(strings.ToLower(column), "_id"):
+		return column[:len(column)-3]
+	case len(column) > 2 && (strings.HasSuffix(column, "Id") || strings.HasSuffix(column, "ID")):
+		return column[:len(column)-2]
+	}
+
+	return ""
+}
+
+// fieldList 返回字段对应的go字段名，本表字段使用去重后的字段名，其他表字段按命名规则转换
+func fieldList(columns []string, columnFields map[string]string, namer *Namer) []string {
+	ret := make([]string, 0, len(columns))
+	for _, column := range columns {
+		name, ok := columnFields[column]
+		if !ok {
+			name = namer.FieldName(column)
+		}
+		ret = append(ret, name)
+	}
+
+	return ret
+}
+
+// getAssociationTag 生成关联字段标签，gorm标签中的外键、关联字段为go字段名
+func getAssociationTag(name string, relation string, fk schema.ForeignKey, foreignKey []string, references []string, config *Config) string {
+	tags := make([]string, 0)
+	switch config.TagDialect {
+	case TagGorm2:
+		tags = append(tags, formatTag("gorm", "foreignKey:"+strings.Join(foreignKey, ",")+";references:"+strings.Join(references, ",")))
+	case TagNone:
+	default:
+		tags = append(tags, formatTag("gorm", "foreignkey:"+strings.Join(foreignKey, ",")+";association_foreignkey:"+strings.Join(references, ",")))
+	}
+	for _, tag := range config.Tags {
+		switch tag {
+		case TagJson:
+			tags = append(tags, formatTag(tag, jsonName(name, config.JsonStyle)+",omitempty"))
+		case TagDb, TagXorm, TagForm:
+			tags = append(tags, formatTag(tag, "-"))
+		case TagBun:
+			tags = append(tags, formatTag(tag, getBunRelation(relation, fk)))
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// getBunRelation 生成bun关联标签，例：rel:belongs-to,join:user_id=id
+func getBunRelation(relation string, fk schema.ForeignKey) string {
+	joins := make([]string, 0, len(fk.Columns))
+	for k := range fk.Columns {
+		if relation == RelationBelongsTo {
+			joins = append(joins, "join:"+fk.Columns[k]+"="+fk.ReferencedColumns[k])
+		} else {
+			joins = append(joins, "join:"+fk.ReferencedColumns[k]+"="+fk.Columns[k])
+		}
+	}
+	rel := "rel:belongs-to"
+	if relation == RelationHasMany {
+		rel = "rel:has-many"
+	}
+
+	return strings.Join(append([]string{rel}, joins...), ",")
+}
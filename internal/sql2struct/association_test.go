@@ -0,0 +1,85 @@
+package sql2struct
+
+import (
+	"strings"
+	"testing"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// parseTables 解析建表语句，返回表名 => 表结构
+func parseTables(t *testing.T, ddl string) map[string]*schema.Table {
+	t.Helper()
+	tables, err := mysql.ParseDdl(ddl)
+	if err != nil {
+		t.Fatalf("ParseDdl() error = %v", err)
+	}
+	ret := make(map[string]*schema.Table, len(tables))
+	for k := range tables {
+		ret[tables[k].Name] = &tables[k]
+	}
+
+	return ret
+}
+
+func TestAssociationFields(t *testing.T) {
+	tables := parseTables(t, `
+CREATE TABLE user (id bigint NOT NULL, PRIMARY KEY (id));
+CREATE TABLE post (
+  id bigint NOT NULL,
+  author_id bigint NOT NULL,
+  editor_id bigint,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_post_author FOREIGN KEY (author_id) REFERENCES user (id),
+  CONSTRAINT fk_post_editor FOREIGN KEY (editor_id) REFERENCES user (id)
+);
+CREATE TABLE category (
+  id bigint NOT NULL,
+  parent_id bigint,
+  parentId bigint,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_category_parent FOREIGN KEY (parentId) REFERENCES category (id)
+);
+`)
+
+	tests := []struct {
+		table   string
+		dialect string
+		want    []string
+	}{
+		{"post", TagGorm2, []string{
+			"Author *User `gorm:\"foreignKey:AuthorID;references:ID\"`",
+			"Editor *User `gorm:\"foreignKey:EditorID;references:ID\"`",
+		}},
+		// 同一父表的两个外键生成两个 HasMany 字段，外键分别为 AuthorID、EditorID
+		{"user", TagGorm2, []string{
+			"Posts []Post `gorm:\"foreignKey:AuthorID;references:ID\"`",
+			"Posts2 []Post `gorm:\"foreignKey:EditorID;references:ID\"`",
+		}},
+		{"user", TagGorm1, []string{
+			"Posts []Post `gorm:\"foreignkey:AuthorID;association_foreignkey:ID\"`",
+			"Posts2 []Post `gorm:\"foreignkey:EditorID;association_foreignkey:ID\"`",
+		}},
+		// parent_id、parentId 的字段名均为 ParentID，外键标签使用去重后的 ParentID2
+		{"category", TagGorm2, []string{
+			"ParentID2 int64 `gorm:\"column:parentId;",
+			"Parent *Category `gorm:\"foreignKey:ParentID2;references:ID\"`",
+			"Categories []Category `gorm:\"foreignKey:ParentID2;references:ID\"`",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table+"/"+tt.dialect, func(t *testing.T) {
+			code, err := GetModelTemplate(tables[tt.table], &Config{TagDialect: tt.dialect, Associations: true, Singular: true, Package: "model"})
+			if err != nil {
+				t.Fatalf("GetModelTemplate() error = %v", err)
+			}
+			// 忽略gofmt对齐的空白
+			normalized := strings.Join(strings.Fields(string(code)), " ")
+			for _, line := range tt.want {
+				if !strings.Contains(normalized, strings.Join(strings.Fields(line), " ")) {
+					t.Errorf("GetModelTemplate(%s) missing %q\n%s", tt.table, line, code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package sql2struct
+// @Title 导入包管理
+// @Description 根据字段实际使用的类型生成导入列表，按标准库、第三方库分组，并移除未使用的导入
+// @Author shigx 2024-06-14 10:22:47
+package sql2struct
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// importSpec 导入包信息
+type importSpec struct {
+	Alias string // 别名，包名与导入路径推断的包名一致时为空
+	Path  string // 导入路径
+}
+
+// newImportSpec
+//
+//	@Description: 生成导入信息，代码中使用的包名与导入路径推断的包名不一致时添加别名
+//	@Auth shigx 2024-06-14 10:22:47
+//	@param path 导入路径
+//	@param name 代码中使用的包名，为空时使用推断的包名
+//	@return importSpec
+func newImportSpec(path string, name string) importSpec {
+	spec := importSpec{Path: path}
+	if name != "" && name != packageName(path) {
+		spec.Alias = name
+	}
+
+	return spec
+}
+
+// name 代码中引用的包名
+func (s importSpec) name() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+
+	return packageName(s.Path)
+}
+
+// String 导入语句
+func (s importSpec) String() string {
+	if s.Alias != "" {
+		return s.Alias + " " + strconv.Quote(s.Path)
+	}
+
+	return strconv.Quote(s.Path)
+}
+
+var (
+	versionRegexp = regexp.MustCompile(`^v[0-9]+$`)
+	gopkgRegexp   = regexp.MustCompile(`\.v[0-9]+$`)
+	identRegexp   = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// packageName
+//
+//	@Description: 按goimports规则由导入路径推断包名，例：gopkg.in/yaml.v3 => yaml，github.com/go-sql-driver/mysql => mysql
+//	@Auth shigx 2024-06-14 10:22:47
+//	@param path
+//	@return string
+func packageName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if versionRegexp.MatchString(name) && len(elems) > 1 {
+		name = elems[len(elems)-2]
+	}
+	name = gopkgRegexp.ReplaceAllString(name, "")
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+
+	return identRegexp.ReplaceAllString(name, "")
+}
+
+// isStdlib 导入路径首段不含.视为标准库
+func isStdlib(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+// importSet 生成文件的导入包集合
+type importSet map[string]importSpec
+
+// add 添加导入包
+func (s importSet) add(specs ...importSpec) {
+	for _, spec := range specs {
+		if _, ok := s[spec.Path]; !ok || spec.Alias != "" {
+			s[spec.Path] = spec
+		}
+	}
+}
+
+// String
+//
+//	@Description: 生成导入语句块，标准库在前、第三方库在后，组间空行分隔
+//	@Auth shigx 2024-06-14 10:22:47
+//	@return string
+func (s importSet) String() string {
+	if len(s) == 0 {
+		return ""
+	}
+
+	specs := make([]importSpec, 0, len(s))
+	for _, spec := range s {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Path < specs[j].Path })
+
+	var std, other []string
+	for _, spec := range specs {
+		if isStdlib(spec.Path) {
+			std = append(std, spec.String())
+		} else {
+			other = append(other, spec.String())
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString("import (\n")
+	for _, item := range std {
+		buf.WriteString("\t" + item + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, item := range other {
+		buf.WriteString("\t" + item + "\n")
+	}
+	buf.WriteString(")")
+
+	return buf.String()
+}
+
+// formatSource
+//
+//	@Description: 格式化生成的代码并移除未使用的导入
+//	@Auth shigx 2024-06-14 10:22:47
+//	@param src
+//	@return []byte
+//	@return error
+func formatSource(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	// 收集代码中引用的包名
+	used := make(map[string]bool)
+	ast.Inspect(f, func(node ast.Node) bool {
+		if sel, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	removed := false
+	for k := 0; k < len(f.Decls); k++ {
+		decl, ok := f.Decls[k].(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		specs := decl.Specs[:0]
+		for _, item := range decl.Specs {
+			spec := item.(*ast.ImportSpec)
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := packageName(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			if used[name] || name == "_" || name == "." {
+				specs = append(specs, spec)
+				continue
+			}
+			removed = true
+		}
+		decl.Specs = specs
+		if len(specs) == 0 {
+			f.Decls = append(f.Decls[:k], f.Decls[k+1:]...)
+			k--
+		}
+	}
+	if !removed {
+		return format.Source(src)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,178 @@
+// Package sql2struct
+// @Title 命名处理
+// @Description 表名、字段名转go标识符，处理常见缩写词、数字开头及保留标识符
+// @Author shigx 2024-06-19 09:48:26
+package sql2struct
+
+import (
+	"github.com/jinzhu/inflection"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms go常见缩写词，与golint保持一致
+var commonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON",
+	"LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID",
+	"UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// reservedFields 与生成的方法重名的字段名
+var reservedFields = map[string]bool{
+	"TableName": true,
+}
+
+// Namer @Description 标识符命名，支持自定义缩写词
+// @Auth shigx
+// @Date 2024-06-19 09:48:26
+type Namer struct {
+	initialisms map[string]bool
+}
+
+// NewNamer
+//
+//	@Description: 创建命名处理，custom为自定义缩写词，与常见缩写词合并
+//	@Auth shigx 2024-06-19 09:48:26
+//	@param custom
+//	@return *Namer
+func NewNamer(custom []string) *Namer {
+	n := &Namer{initialisms: make(map[string]bool)}
+	for _, word := range commonInitialisms {
+		n.initialisms[word] = true
+	}
+	for _, word := range custom {
+		if word = strings.TrimSpace(word); word != "" {
+			n.initialisms[strings.ToUpper(word)] = true
+		}
+	}
+
+	return n
+}
+
+// Name
+//
+//	@Description: 转为导出的go标识符，例：user_id => UserID，api_url => APIURL，2fa_code => X2faCode
+//	@Auth shigx 2024-06-19 09:48:26
+//	@param s
+//	@return string
+func (n *Namer) Name(s string) string {
+	var buf strings.Builder
+	for _, word := range splitWords(s) {
+		upper := strings.ToUpper(word)
+		if n.initialisms[upper] {
+			buf.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		buf.WriteString(string(runes))
+	}
+
+	name := buf.String()
+	if name == "" {
+		return "X"
+	}
+	// 数字开头或首字母无大小写（如中文）时无法导出，添加前缀
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		name = "X" + name
+	}
+
+	return name
+}
+
+// FieldName
+//
+//	@Description: 转为字段名，与生成的方法重名时添加下划线后缀
+//	@Auth shigx 2024-06-19 09:48:26
+//	@param s
+//	@return string
+func (n *Namer) FieldName(s string) string {
+	name := n.Name(s)
+	if reservedFields[name] {
+		name += "_"
+	}
+
+	return name
+}
+
+// splitWords 按非字母数字字符及驼峰边界拆分单词，例：userID_list => user ID list
+func splitWords(s string) []string {
+	words := make([]string, 0)
+	runes := []rune(s)
+	start := -1
+	for k, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:k]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = k
+			continue
+		}
+		prev := runes[k-1]
+		nextLower := k+1 < len(runes) && unicode.IsLower(runes[k+1])
+		// 小写后接大写：userId；连续大写后接小写：HTTPServer
+		if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)) {
+			words = append(words, string(runes[start:k]))
+			start = k
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// StructName
+//
+//	@Description: 表名转结构体名，依次处理去除表前缀、单数化、添加前后缀
+//	@Auth shigx 2024-06-21 14:12:09
+//	@param tableName
+//	@param config
+//	@return string
+func (n *Namer) StructName(tableName string, config *Config) string {
+	name := tableName
+	for _, prefix := range config.TrimPrefix {
+		if prefix != "" && strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			name = strings.TrimPrefix(name, prefix)
+			break
+		}
+	}
+	if config.Singular {
+		name = inflection.Singular(name)
+	}
+
+	return n.Name(config.StructPrefix + "_" + name + "_" + config.StructSuffix)
+}
+
+// PackageName
+//
+//	@Description: 转为合法的包名，仅保留小写字母、数字及下划线，数字开头添加p前缀，关键字添加下划线后缀
+//	@Auth shigx 2024-06-21 14:12:09
+//	@param s
+//	@return string
+func PackageName(s string) string {
+	var buf strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			buf.WriteRune(r)
+		}
+	}
+
+	name := buf.String()
+	switch {
+	case name == "":
+		return "model"
+	case name[0] >= '0' && name[0] <= '9':
+		name = "p" + name
+	case token.IsKeyword(name):
+		name += "_"
+	}
+
+	return name
+}
@@ -0,0 +1,48 @@
+package sql2struct
+
+import "testing"
+
+func TestNamerFieldName(t *testing.T) {
+	namer := NewNamer([]string{"sku", " "})
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"user_id", "UserID"},
+		{"api_url", "APIURL"},
+		{"http_server_ip", "HTTPServerIP"},
+		{"user_uuid_json", "UserUUIDJSON"},
+		{"sku_id", "SKUID"},
+		{"userId", "UserID"},
+		{"HTTPServer", "HTTPServer"},
+		{"2fa_code", "X2faCode"},
+		{"type", "Type"},
+		{"func", "Func"},
+		{"名称", "X名称"},
+		{"__", "X"},
+		{"table_name", "TableName_"},
+	}
+	for _, tt := range tests {
+		if got := namer.FieldName(tt.in); got != tt.want {
+			t.Errorf("FieldName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"order_item", "order_item"},
+		{"Order-Item", "orderitem"},
+		{"2024_log", "p2024_log"},
+		{"type", "type_"},
+		{"map", "map_"},
+	}
+	for _, tt := range tests {
+		if got := PackageName(tt.in); got != tt.want {
+			t.Errorf("PackageName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
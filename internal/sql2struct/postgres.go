@@ -0,0 +1,97 @@
+// Package sql2struct
+// @Title postgresql字段类型转换
+// @Description postgresql数据类型（pg_type 类型名）转go类型，数组使用 github.com/lib/pq 的数组类型
+// @Author shigx 2024-08-19 14:26:37
+package sql2struct
+
+import (
+	"strings"
+	"tool-cli/internal/postgres"
+)
+
+var postgresTypeToGoType = map[string]string{
+	"bool":        "bool",
+	"int2":        "int16",
+	"int4":        "int32",
+	"int8":        "int64",
+	"oid":         "uint32",
+	"float4":      "float32",
+	"float8":      "float64",
+	"numeric":     "float64",
+	"money":       "string",
+	"char":        "string",
+	"bpchar":      "string",
+	"varchar":     "string",
+	"text":        "string",
+	"name":        "string",
+	"citext":      "string",
+	"xml":         "string",
+	"uuid":        "string",
+	"inet":        "string",
+	"cidr":        "string",
+	"macaddr":     "string",
+	"macaddr8":    "string",
+	"interval":    "string",
+	"time":        "string",
+	"timetz":      "string",
+	"date":        "time.Time",
+	"timestamp":   "time.Time",
+	"timestamptz": "time.Time",
+	"bytea":       "[]byte",
+	"bit":         "string",
+	"varbit":      "string",
+	"tsvector":    "string",
+	"json":        "json.RawMessage",
+	"jsonb":       "json.RawMessage",
+	"point":       "string",
+	"geometry":    "[]byte",
+	"vector":      "string",
+}
+
+// postgresArrayTypes 数组元素类型对应的 pq 数组类型，其余元素类型使用 pq.StringArray
+var postgresArrayTypes = map[string]string{
+	"bool":   "pq.BoolArray",
+	"int2":   "pq.Int32Array",
+	"int4":   "pq.Int32Array",
+	"int8":   "pq.Int64Array",
+	"float4": "pq.Float32Array",
+	"float8": "pq.Float64Array",
+	"bytea":  "pq.ByteaArray",
+}
+
+// getPostgresType
+//
+//	@Description: 根据postgresql数据类型返回go类型，枚举为string，json、numeric按配置转换，不支持的类型返回空
+//	@Auth shigx 2024-08-19 14:26:37
+//	@param dataType
+//	@param config
+//	@return string
+func getPostgresType(dataType string, config *Config) string {
+	if element, ok := strings.CutSuffix(dataType, "[]"); ok {
+		if name, ok := postgresArrayTypes[element]; ok {
+			return name
+		}
+		return "pq.StringArray"
+	}
+
+	switch dataType {
+	case postgres.DataTypeEnum:
+		return "string"
+	case "json", "jsonb":
+		switch config.JsonType {
+		case JsonDatatypes:
+			return "datatypes.JSON"
+		case JsonString:
+			return "string"
+		}
+	case "numeric":
+		switch config.DecimalType {
+		case DecimalString:
+			return "string"
+		case DecimalDecimal:
+			return "decimal.Decimal"
+		}
+	}
+
+	return postgresTypeToGoType[dataType]
+}
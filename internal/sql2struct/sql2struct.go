@@ -7,38 +7,52 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/pkg/errors"
-	"go/format"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-	"strings"
-	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
 )
 
 var mysqlTypeToGoType = map[string]string{
-	"tinyint":    "int64",
-	"smallint":   "int64",
-	"mediumint":  "int64",
-	"int":        "int64",
-	"integer":    "int64",
-	"bigint":     "int64",
-	"float":      "float64",
-	"double":     "float64",
-	"decimal":    "float64",
-	"date":       "time.Time",
-	"time":       "string",
-	"year":       "string",
-	"datetime":   "time.Time",
-	"timestamp":  "time.Time",
-	"char":       "string",
-	"varchar":    "string",
-	"tinyblob":   "string",
-	"tinytext":   "string",
-	"blob":       "string",
-	"text":       "string",
-	"mediumblob": "string",
-	"mediumtext": "string",
-	"longblob":   "string",
-	"longtext":   "string",
+	"bit":                "[]byte",
+	"tinyint":            "int64",
+	"smallint":           "int64",
+	"mediumint":          "int64",
+	"int":                "int64",
+	"integer":            "int64",
+	"bigint":             "int64",
+	"float":              "float64",
+	"double":             "float64",
+	"real":               "float64",
+	"decimal":            "float64",
+	"numeric":            "float64",
+	"date":               "time.Time",
+	"time":               "string",
+	"year":               "string",
+	"datetime":           "time.Time",
+	"timestamp":          "time.Time",
+	"char":               "string",
+	"varchar":            "string",
+	"binary":             "[]byte",
+	"varbinary":          "[]byte",
+	"tinyblob":           "[]byte",
+	"tinytext":           "string",
+	"blob":               "[]byte",
+	"text":               "string",
+	"mediumblob":         "[]byte",
+	"mediumtext":         "string",
+	"longblob":           "[]byte",
+	"longtext":           "string",
+	"enum":               "string",
+	"set":                "string",
+	"json":               "json.RawMessage",
+	"geometry":           "[]byte",
+	"point":              "[]byte",
+	"linestring":         "[]byte",
+	"polygon":            "[]byte",
+	"multipoint":         "[]byte",
+	"multilinestring":    "[]byte",
+	"multipolygon":       "[]byte",
+	"geometrycollection": "[]byte",
+	"geomcollection":     "[]byte",
+	"vector":             "[]byte",
 }
 
 // GetModelTemplate
@@ -47,25 +61,57 @@ var mysqlTypeToGoType = map[string]string{
 // @Date 2022/4/20 6:42 下午
 // @param
 // @return
-func GetModelTemplate(columns []mysql.TableColumn, tableName string, tableComment string) ([]byte, error) {
-	t, err := GetTemplate()
-
+func GetModelTemplate(table *schema.Table, config *Config) ([]byte, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	t, err := GetTemplate(config.Template)
 	if err != nil {
-		return nil, errors.Wrap(err, "template init err")
+		return nil, err
+	}
+
+	var (
+		tableName  = table.Name
+		fields     = make([]Field, 0, len(table.Columns))
+		imports    = make(importSet)
+		namer      = NewNamer(config.Initialisms)
+		fieldNames = make(map[string]bool)
+		// 字段名 => 去重后的go字段名，关联字段标签引用本表字段时使用
+		columnFields = make(map[string]string)
+	)
+	for _, row := range table.Columns {
+		fieldType, err := getColumnType(tableName, row, config)
+		if err != nil {
+			return nil, err
+		}
+		imports.add(fieldType.Imports...)
+		name := uniqueName(fieldNames, namer.FieldName(row.ColumnName))
+		columnFields[row.ColumnName] = name
+		fields = append(fields, Field{
+			Name:    name,
+			Type:    fieldType.Name,
+			Tag:     getTagContent(row, columnIndexes(table.Indexes, row.ColumnName), config),
+			Comment: row.ColumnComment.String,
+			Column:  row,
+		})
+	}
+	if config.Associations {
+		fields = append(fields, getAssociationFields(table, config, namer, fieldNames, columnFields)...)
 	}
 
-	var structContent = make([]string, 0)
-	for _, row := range columns {
-		str := fmt.Sprintf("%s %s %s", Capitalize(row.ColumnName), TextToType(row.DataType), getGormContent(row))
-		structContent = append(structContent, str)
+	pkg := config.Package
+	if pkg == "" {
+		pkg = PackageName(tableName)
 	}
 
-	data := map[string]interface{}{
-		"pkg":           tableName,
-		"structName":    Capitalize(tableName),
-		"structComment": tableComment,
-		"structContent": structContent,
-		"tableName":     tableName,
+	data := &Model{
+		Package:    pkg,
+		Imports:    imports.String(),
+		StructName: namer.StructName(tableName, config),
+		TableName:  tableName,
+		Comment:    table.Comment,
+		Fields:     fields,
+		Indexes:    table.Indexes,
 	}
 
 	buffer := bytes.NewBufferString("")
@@ -74,43 +120,28 @@ func GetModelTemplate(columns []mysql.TableColumn, tableName string, tableCommen
 		return nil, errors.WithMessage(err, "template data err")
 	}
 
-	return format.Source(buffer.Bytes())
+	return formatSource(buffer.Bytes())
 }
 
-// getGormContent
-//
-//	@Description: 字段生成gorm信息
-//	@Auth shigx 2024-05-15 09:01:09
-//	@param row
-//	@return string
-func getGormContent(row mysql.TableColumn) string {
-	str := fmt.Sprintf("`gorm:\"column:%s", row.ColumnName)
-	if row.ColumnKey.String == "PRI" {
-		str += ";primary_key"
-	}
-	if row.Extra.String == "auto_increment" {
-		str += ";AUTO_INCREMENT"
+// uniqueName 不同字段转换后重名时添加序号
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+	for k := 2; used[unique]; k++ {
+		unique = fmt.Sprintf("%s%d", name, k)
 	}
-	if row.IsNullable == "NO" {
-		str += ";NOT NULL"
-	}
-
-	str += ";default:" + row.ColumnDefault.String + ";comment:'" + strings.ReplaceAll(row.ColumnComment.String, "\n", "") + "'\"`"
+	used[unique] = true
 
-	return str
+	return unique
 }
 
 // Capitalize
-// @Description 带下划线字符串转首字母大写驼峰
+// @Description 带下划线字符串转首字母大写驼峰，处理常见缩写词，例：user_id => UserID
 // @Auth shigx
 // @Date 2022/3/24 10:04 下午
 // @param
 // @return
 func Capitalize(s string) string {
-	s = strings.ReplaceAll(s, "_", " ")
-	titleCaser := cases.Title(language.English)
-	s = titleCaser.String(s)
-	return strings.ReplaceAll(s, " ", "")
+	return NewNamer(nil).Name(s)
 }
 
 // TextToType @Description mysql类型转go结构体类型
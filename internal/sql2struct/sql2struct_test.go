@@ -0,0 +1,110 @@
+package sql2struct
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+)
+
+// update 重新生成 testdata 下的 .golden 文件：go test ./internal/sql2struct -update
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden 比较生成结果与 testdata 下的 golden 文件
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+// readTable 读取 testdata 下只包含一张表的建表语句
+func readTable(t *testing.T, name string) *schema.Table {
+	t.Helper()
+	tables, err := mysql.ReadDdl(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadDdl() error = %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("ReadDdl() = %d tables, want 1", len(tables))
+	}
+
+	return &tables[0]
+}
+
+// modelGoldenTest 生成结构体的golden用例
+type modelGoldenTest struct {
+	golden string
+	config Config
+}
+
+// runModelGolden 按各配置生成结构体并与golden文件比较
+func runModelGolden(t *testing.T, table *schema.Table, tests []modelGoldenTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			config := tt.config
+			config.Package = "model"
+			code, err := GetModelTemplate(table, &config)
+			if err != nil {
+				t.Fatalf("GetModelTemplate() error = %v", err)
+			}
+			assertGolden(t, tt.golden, string(code))
+		})
+	}
+}
+
+func TestGetModelTemplateNullable(t *testing.T) {
+	// 不输出gorm标签，只比较可空字段的类型及导入
+	runModelGolden(t, readTable(t, "model.sql"), []modelGoldenTest{
+		{"nullable_none.golden", Config{TagDialect: TagNone, Nullable: NullableNone}},
+		{"nullable_sql.golden", Config{TagDialect: TagNone, Nullable: NullableSql}},
+		{"nullable_pointer.golden", Config{TagDialect: TagNone, Nullable: NullablePointer}},
+		{"nullable_gorm.golden", Config{TagDialect: TagNone, Nullable: NullableGorm}},
+	})
+}
+
+func TestGetModelTemplateTypes(t *testing.T) {
+	// 不输出gorm标签，只比较字段类型及导入
+	runModelGolden(t, readTable(t, "types.sql"), []modelGoldenTest{
+		{"types.golden", Config{TagDialect: TagNone}},
+		{"types_decimal_datatypes.golden", Config{TagDialect: TagNone, TinyintBool: true, DecimalType: DecimalDecimal, JsonType: JsonDatatypes}},
+		{"types_string.golden", Config{TagDialect: TagNone, DecimalType: DecimalString, JsonType: JsonString}},
+	})
+}
+
+func TestGetModelTemplateTags(t *testing.T) {
+	runModelGolden(t, readTable(t, "model.sql"), []modelGoldenTest{
+		{"tags_gorm1.golden", Config{TagDialect: TagGorm1}},
+		{"tags_gorm2.golden", Config{TagDialect: TagGorm2}},
+		{"tags_all.golden", Config{TagDialect: TagGorm2, Tags: []string{TagJson, TagDb, TagXorm, TagBun, TagForm}}},
+		{"tags_json_camel.golden", Config{TagDialect: TagNone, Tags: []string{TagJson}, JsonStyle: JsonStyleCamel}},
+	})
+}
+
+func TestGetModelTemplateInitialisms(t *testing.T) {
+	runModelGolden(t, readTable(t, "model.sql"), []modelGoldenTest{
+		{"initialisms.golden", Config{TagDialect: TagNone, Initialisms: []string{"SKU"}}},
+	})
+}
+
+func TestGetModelTemplateIndexes(t *testing.T) {
+	// 函数索引无法用gorm标签表示，不输出
+	runModelGolden(t, readTable(t, "indexes.sql"), []modelGoldenTest{
+		{"indexes_gorm1.golden", Config{TagDialect: TagGorm1}},
+		{"indexes_gorm2.golden", Config{TagDialect: TagGorm2}},
+	})
+}
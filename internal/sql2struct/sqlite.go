@@ -0,0 +1,62 @@
+// Package sql2struct
+// @Title sqlite字段类型转换
+// @Description 按sqlite的类型亲和性规则将声明类型转为go类型，常见的布尔、时间、json、decimal声明类型单独处理
+// @Author shigx 2024-08-26 10:15:42
+package sql2struct
+
+import (
+	"strings"
+)
+
+// sqliteTypeToGoType 按声明类型名转换的类型，优先于亲和性规则
+var sqliteTypeToGoType = map[string]string{
+	"boolean":   "bool",
+	"bool":      "bool",
+	"date":      "time.Time",
+	"datetime":  "time.Time",
+	"timestamp": "time.Time",
+	"time":      "string",
+	"json":      "json.RawMessage",
+	"decimal":   "float64",
+	"numeric":   "float64",
+}
+
+// getSqliteType
+//
+//	@Description: 根据sqlite声明类型返回go类型，按类型亲和性：包含INT为int64，包含CHAR、CLOB、TEXT为string，包含BLOB或未声明为[]byte，包含REAL、FLOA、DOUB为float64，其余为float64
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param dataType 小写的声明类型名，不含长度
+//	@param config
+//	@return string
+func getSqliteType(dataType string, config *Config) string {
+	switch dataType {
+	case "json":
+		switch config.JsonType {
+		case JsonDatatypes:
+			return "datatypes.JSON"
+		case JsonString:
+			return "string"
+		}
+	case "decimal", "numeric":
+		switch config.DecimalType {
+		case DecimalString:
+			return "string"
+		case DecimalDecimal:
+			return "decimal.Decimal"
+		}
+	}
+	if name, ok := sqliteTypeToGoType[dataType]; ok {
+		return name
+	}
+
+	switch {
+	case strings.Contains(dataType, "int"):
+		return "int64"
+	case strings.Contains(dataType, "char"), strings.Contains(dataType, "clob"), strings.Contains(dataType, "text"):
+		return "string"
+	case strings.Contains(dataType, "blob"), dataType == "":
+		return "[]byte"
+	}
+
+	return "float64"
+}
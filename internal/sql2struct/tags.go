@@ -0,0 +1,343 @@
+// Package sql2struct
+// @Title 结构体标签生成
+// @Description 按配置生成gorm（v1、v2）、json、db、xorm、bun、form标签
+// @Author shigx 2024-06-17 16:40:05
+package sql2struct
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/tmpl"
+)
+
+// gorm标签风格
+const (
+	TagGorm1 = "gorm1" // gorm v1：primary_key;AUTO_INCREMENT;NOT NULL
+	TagGorm2 = "gorm2" // gorm v2：primaryKey;autoIncrement;not null
+	TagNone  = "none"  // 不输出gorm标签
+)
+
+// json、form标签命名风格
+const (
+	JsonStyleSnake = "snake" // user_id
+	JsonStyleCamel = "camel" // userId
+)
+
+// 支持的额外标签
+const (
+	TagJson = "json"
+	TagDb   = "db"
+	TagXorm = "xorm"
+	TagBun  = "bun"
+	TagForm = "form"
+)
+
+// numericTypes 数值、布尔类型，默认值不加引号
+var numericTypes = map[string]bool{
+	"bit": true, "tinyint": true, "smallint": true, "mediumint": true, "int": true, "integer": true, "bigint": true,
+	"float": true, "double": true, "real": true, "decimal": true, "numeric": true,
+	"int2": true, "int4": true, "int8": true, "float4": true, "float8": true, "bool": true, "boolean": true,
+}
+
+// temporalTypes 日期时间类型，CURRENT_TIMESTAMP、NOW() 等默认值按表达式处理
+var temporalTypes = map[string]bool{
+	"date": true, "time": true, "datetime": true, "timestamp": true, "year": true,
+	"timetz": true, "timestamptz": true,
+}
+
+// sizedTypes 有长度的类型，gorm v2输出size
+var sizedTypes = map[string]bool{
+	"char": true, "varchar": true, "binary": true, "varbinary": true, "bpchar": true,
+}
+
+// getTagContent
+//
+//	@Description: 字段生成标签信息
+//	@Auth shigx 2024-06-17 16:40:05
+//	@param row
+//	@param indexes 字段所在的索引
+//	@param config
+//	@return string
+func getTagContent(row schema.TableColumn, indexes []fieldIndex, config *Config) string {
+	tags := make([]string, 0)
+	switch config.TagDialect {
+	case TagGorm2:
+		tags = append(tags, formatTag("gorm", getGorm2Content(row, indexes)))
+	case TagNone:
+	default:
+		tags = append(tags, formatTag("gorm", getGormContent(row, indexes)))
+	}
+
+	for _, tag := range config.Tags {
+		switch tag {
+		case TagJson, TagForm:
+			tags = append(tags, formatTag(tag, jsonName(row.ColumnName, config.JsonStyle)))
+		case TagDb:
+			tags = append(tags, formatTag(tag, row.ColumnName))
+		case TagXorm:
+			tags = append(tags, formatTag(tag, getXormContent(row, indexes)))
+		case TagBun:
+			tags = append(tags, formatTag(tag, getBunContent(row, indexes)))
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// formatTag 生成 key:"value" 形式的标签，反引号无法出现在原始字符串中，替换为单引号
+func formatTag(key string, value string) string {
+	return key + ":" + strconv.Quote(strings.ReplaceAll(value, "`", "'"))
+}
+
+// getGormContent
+//
+//	@Description: 字段生成gorm v1标签信息
+//	@Auth shigx 2024-05-15 09:01:09
+//	@param row
+//	@param indexes
+//	@return string
+func getGormContent(row schema.TableColumn, indexes []fieldIndex) string {
+	str := "column:" + row.ColumnName
+	if row.ColumnKey.String == "PRI" {
+		str += ";primary_key"
+	}
+	if isAutoIncrement(row) {
+		str += ";AUTO_INCREMENT"
+	}
+	if row.IsNullable == "NO" {
+		str += ";NOT NULL"
+	}
+	for _, index := range indexes {
+		if index.Unique {
+			str += ";unique_index:" + index.Name
+		} else {
+			str += ";index:" + index.Name
+		}
+	}
+	if value, ok := defaultValue(row); ok {
+		str += ";default:" + gormEscape(value)
+	}
+	if comment := columnComment(row); comment != "" {
+		str += ";comment:'" + gormEscape(comment) + "'"
+	}
+
+	return str
+}
+
+// getGorm2Content
+//
+//	@Description: 字段生成gorm v2标签信息
+//	@Auth shigx 2024-06-17 16:40:05
+//	@param row
+//	@param indexes
+//	@return string
+func getGorm2Content(row schema.TableColumn, indexes []fieldIndex) string {
+	items := []string{"column:" + row.ColumnName, "type:" + row.ColumnType}
+	if size := columnSize(row); size != "" {
+		items = append(items, "size:"+size)
+	}
+	if row.ColumnKey.String == "PRI" {
+		items = append(items, "primaryKey")
+	}
+	if isAutoIncrement(row) {
+		items = append(items, "autoIncrement")
+	}
+	if row.IsNullable == "NO" {
+		items = append(items, "not null")
+	}
+	for _, index := range indexes {
+		item := "index:" + index.Name
+		if index.Unique {
+			item = "uniqueIndex:" + index.Name
+		}
+		// 组合索引指定字段顺序
+		if index.Size > 1 {
+			item += fmt.Sprintf(",priority:%d", index.Priority)
+		}
+		if index.Type == "FULLTEXT" || index.Type == "SPATIAL" {
+			item += ",class:" + index.Type
+		} else if index.Type == "HASH" {
+			item += ",type:hash"
+		}
+		if index.Length > 0 {
+			item += fmt.Sprintf(",length:%d", index.Length)
+		}
+		items = append(items, item)
+	}
+	if value, ok := defaultValue(row); ok {
+		items = append(items, "default:"+gormEscape(value))
+	}
+	if comment := columnComment(row); comment != "" {
+		items = append(items, "comment:"+gormEscape(comment))
+	}
+
+	return strings.Join(items, ";")
+}
+
+// getXormContent
+//
+//	@Description: 字段生成xorm标签信息
+//	@Auth shigx 2024-06-17 16:40:05
+//	@param row
+//	@param indexes
+//	@return string
+func getXormContent(row schema.TableColumn, indexes []fieldIndex) string {
+	items := []string{"'" + row.ColumnName + "'"}
+	if fields := strings.Fields(row.ColumnType); len(fields) > 0 {
+		items = append(items, fields[0])
+	}
+	if row.ColumnKey.String == "PRI" {
+		items = append(items, "pk")
+	}
+	if isAutoIncrement(row) {
+		items = append(items, "autoincr")
+	}
+	if row.IsNullable == "NO" {
+		items = append(items, "notnull")
+	}
+	if value, ok := defaultValue(row); ok && !isAutoIncrement(row) {
+		items = append(items, "default "+value)
+	}
+	for _, index := range indexes {
+		if index.Unique {
+			items = append(items, "unique("+index.Name+")")
+		} else {
+			items = append(items, "index("+index.Name+")")
+		}
+	}
+
+	return strings.Join(items, " ")
+}
+
+// getBunContent
+//
+//	@Description: 字段生成bun标签信息
+//	@Auth shigx 2024-06-17 16:40:05
+//	@param row
+//	@param indexes
+//	@return string
+func getBunContent(row schema.TableColumn, indexes []fieldIndex) string {
+	items := []string{row.ColumnName}
+	if row.ColumnKey.String == "PRI" {
+		items = append(items, "pk")
+	}
+	if isAutoIncrement(row) {
+		items = append(items, "autoincrement")
+	}
+	if row.IsNullable == "NO" {
+		items = append(items, "notnull")
+	}
+	items = append(items, "type:"+row.ColumnType)
+	for _, index := range indexes {
+		if index.Unique {
+			items = append(items, "unique:"+index.Name)
+		}
+	}
+
+	return strings.Join(items, ",")
+}
+
+// fieldIndex 字段所在的索引信息
+type fieldIndex struct {
+	Name     string // 索引名
+	Unique   bool   // 是否唯一索引
+	Type     string // 索引类型
+	Size     int    // 索引字段数
+	Priority int    // 字段在索引中的顺序，从1开始
+	Length   int64  // 前缀索引长度
+}
+
+// columnIndexes 返回字段所在的索引，主键由字段键处理不包含在内，gorm标签无法表示的表达式索引不包含在内
+func columnIndexes(indexes []schema.TableIndex, column string) []fieldIndex {
+	ret := make([]fieldIndex, 0)
+	for _, index := range indexes {
+		if index.IsPrimary() || len(index.ColumnNames()) < len(index.Columns) {
+			continue
+		}
+		for k, item := range index.Columns {
+			if strings.EqualFold(item.Name, column) {
+				ret = append(ret, fieldIndex{
+					Name:     index.Name,
+					Unique:   index.Unique,
+					Type:     index.Type,
+					Size:     len(index.Columns),
+					Priority: k + 1,
+					Length:   item.Length,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// isAutoIncrement 是否自增字段
+func isAutoIncrement(row schema.TableColumn) bool {
+	return row.AutoIncrement
+}
+
+// columnComment 去除换行后的字段备注
+func columnComment(row schema.TableColumn) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(row.ColumnComment.String)
+}
+
+// columnSize 返回字符、二进制类型的长度
+func columnSize(row schema.TableColumn) string {
+	if !sizedTypes[row.DataType] {
+		return ""
+	}
+	start, end := strings.Index(row.ColumnType, "("), strings.Index(row.ColumnType, ")")
+	if start < 0 || end < start {
+		return ""
+	}
+
+	return row.ColumnType[start+1 : end]
+}
+
+// defaultValue
+//
+//	@Description: 返回sql形式的默认值，字符串类型加单引号，数值及表达式保持原样，无默认值返回false，
+//	未标记为表达式的 CURRENT_TIMESTAMP 等只在日期时间类型中视为表达式，其他类型按字符串常量处理
+//	@Auth shigx 2024-06-17 16:40:05
+//	@param row
+//	@return string
+//	@return bool
+func defaultValue(row schema.TableColumn) (string, bool) {
+	if !row.ColumnDefault.Valid {
+		return "", false
+	}
+
+	value := row.ColumnDefault.String
+	upper := strings.ToUpper(value)
+	switch {
+	case numericTypes[row.DataType] && value != "":
+		return value, true
+	case row.DefaultExpr:
+		return value, true
+	case temporalTypes[strings.ToLower(row.DataType)] && (strings.HasPrefix(upper, "CURRENT_TIMESTAMP") ||
+		strings.HasPrefix(upper, "CURRENT_DATE") || strings.HasPrefix(upper, "CURRENT_TIME") ||
+		strings.HasPrefix(upper, "LOCALTIME") || strings.HasPrefix(upper, "NOW(")):
+		return value, true
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'", true
+}
+
+// gormEscape 转义gorm标签中的分号
+func gormEscape(s string) string {
+	return strings.ReplaceAll(s, ";", `\;`)
+}
+
+// jsonName 按命名风格返回json、form标签名
+func jsonName(column string, style string) string {
+	if style == JsonStyleCamel {
+		return tmpl.Camel(column)
+	}
+
+	return tmpl.Snake(column)
+}
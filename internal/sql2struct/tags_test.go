@@ -0,0 +1,39 @@
+package sql2struct
+
+import (
+	"database/sql"
+	"testing"
+	"tool-cli/internal/schema"
+)
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		dataType string
+		value    string
+		expr     bool
+		want     string
+	}{
+		{"int", "0", false, "0"},
+		{"varchar", "", false, "''"},
+		{"varchar", "it's", false, "'it''s'"},
+		{"datetime", "CURRENT_TIMESTAMP", false, "CURRENT_TIMESTAMP"},
+		{"timestamp", "current_timestamp(3)", false, "current_timestamp(3)"},
+		{"date", "CURRENT_DATE", false, "CURRENT_DATE"},
+		{"timestamptz", "now()", true, "now()"},
+		{"varchar", "uuid()", true, "uuid()"},
+		// 非日期时间类型的同名字符串常量
+		{"varchar", "CURRENT_TIMESTAMP", false, "'CURRENT_TIMESTAMP'"},
+		{"varchar", "NOW()", false, "'NOW()'"},
+		{"text", "LOCALTIME", false, "'LOCALTIME'"},
+	}
+	for _, tt := range tests {
+		row := schema.TableColumn{DataType: tt.dataType, ColumnDefault: sql.NullString{String: tt.value, Valid: true}, DefaultExpr: tt.expr}
+		if got, ok := defaultValue(row); !ok || got != tt.want {
+			t.Errorf("defaultValue(%s %q) = %q, %v, want %q", tt.dataType, tt.value, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := defaultValue(schema.TableColumn{DataType: "varchar"}); ok {
+		t.Error("defaultValue() without default = true, want false")
+	}
+}
@@ -1,42 +1,64 @@
 // Package sql2struct
 // @Title 输出模版定义
-// @Description 请填写文件描述（需要改）
+// @Description 默认模版及模版数据定义，支持自定义模版文件
 // @Author shigx 2022/4/20 6:13 下午
 package sql2struct
 
-import "html/template"
+import (
+	"text/template"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/tmpl"
+)
 
 const tpl = `// Code generated by tool-cli DO NOT EDIT
-package {{.pkg}}
+package {{.Package}}
+{{- if .Imports}}
 
-import "time"
+{{.Imports}}
+{{- end}}
 
-// {{.structName}} {{.structComment}}
-type {{.structName}} struct {
-	{{- range $key, $value := .structContent}}
-	{{$value | unescaped}}
+// {{.StructName}} {{oneline .Comment}}
+type {{.StructName}} struct {
+	{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
 	{{- end}}
 }
 
-func ({{.structName}}) TableName() string {
-	return "{{.tableName}}"
+func ({{.StructName}}) TableName() string {
+	return "{{.TableName}}"
 }`
 
-// GetTemplate
-// @Description 返回模版
+// Model @Description 模版数据，自定义模版可使用全部字段及 tmpl.FuncMap 中的函数
 // @Auth shigx
-// @Date 2022/4/20 6:25 下午
-// @param
-// @return
-func GetTemplate() (*template.Template, error) {
-	return template.New("output_template").Funcs(template.FuncMap{"unescaped": unescaped}).Parse(tpl)
+// @Date 2024-06-24 10:30:52
+type Model struct {
+	Package    string              // 包名
+	Imports    string              // 导入语句块，按字段使用的类型生成，未使用的导入在格式化时移除
+	StructName string              // 结构体名
+	TableName  string              // 表名
+	Comment    string              // 表备注
+	Fields     []Field             // 字段列表
+	Indexes    []schema.TableIndex // 索引列表，主键排在最前
+}
+
+// Field @Description 模版字段数据
+// @Auth shigx
+// @Date 2024-06-24 10:30:52
+type Field struct {
+	Name     string             // go字段名，例：UserID
+	Type     string             // go类型，例：sql.NullString
+	Tag      string             // 完整标签，含反引号
+	Comment  string             // 字段备注
+	Column   schema.TableColumn // 原始字段信息，关联字段为空
+	Relation string             // 关联类型：belongs_to、has_many，普通字段为空
 }
 
-// @Description 字符串不转译函数
+// GetTemplate
+// @Description 返回模版，file不为空时使用自定义模版文件
 // @Auth shigx
-// @Date 2022/4/20 11:09 下午
-// @param
+// @Date 2022/4/20 6:25 下午
+// @param file
 // @return
-func unescaped(str string) template.HTML {
-	return template.HTML(str)
+func GetTemplate(file string) (*template.Template, error) {
+	return tmpl.Parse("output_template", file, tpl)
 }
@@ -0,0 +1,17 @@
+-- 覆盖组合主键、组合唯一索引、单字段唯一索引、前缀索引、同一字段属于多个索引及函数索引
+CREATE TABLE `member` (
+  `tenant_id` int NOT NULL,
+  `id` bigint NOT NULL,
+  `email` varchar(128) NOT NULL,
+  `phone` varchar(32) NOT NULL,
+  `nickname` varchar(64) NOT NULL,
+  `status` tinyint NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`tenant_id`,`id`),
+  UNIQUE KEY `uk_tenant_email` (`tenant_id`,`email`),
+  UNIQUE KEY `uk_phone` (`phone`),
+  KEY `idx_status_created` (`status`,`created_at`),
+  KEY `idx_nickname` (`nickname`(16)),
+  KEY `idx_created` (`created_at`),
+  KEY `idx_lower_email` ((lower(`email`)))
+) ENGINE=InnoDB COMMENT='会员';
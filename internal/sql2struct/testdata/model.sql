@@ -0,0 +1,26 @@
+-- 覆盖索引前缀长度及顺序、唯一索引、组合主键、可空字段、各类字段类型及缩写词命名
+CREATE TABLE `order_item` (
+  `order_id` bigint unsigned NOT NULL COMMENT '订单ID',
+  `sku_id` int NOT NULL COMMENT 'SKU',
+  `user_uuid` char(36) NOT NULL DEFAULT '' COMMENT '用户UUID',
+  `api_url` varchar(255) DEFAULT NULL COMMENT '回调地址',
+  `ip_addr` varchar(64) NOT NULL DEFAULT '',
+  `title` varchar(128) NOT NULL DEFAULT '' COMMENT '标题',
+  `is_gift` tinyint(1) NOT NULL DEFAULT '0',
+  `qty` smallint DEFAULT NULL,
+  `price` decimal(10,2) NOT NULL DEFAULT '0.00',
+  `discount` decimal(10,2) DEFAULT NULL,
+  `rate` double DEFAULT NULL,
+  `extra` json DEFAULT NULL,
+  `status` enum('new','paid','closed') NOT NULL DEFAULT 'new',
+  `remark` text,
+  `paid_at` datetime DEFAULT NULL,
+  `ship_date` date DEFAULT NULL,
+  `raw` blob,
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`order_id`,`sku_id`),
+  UNIQUE KEY `uk_user_uuid` (`user_uuid`),
+  KEY `idx_a_b` (`ip_addr`,`title`(10)),
+  KEY `idx_paid_at` (`paid_at`)
+) ENGINE=InnoDB COMMENT='订单明细';
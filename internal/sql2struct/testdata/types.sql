@@ -0,0 +1,30 @@
+-- 覆盖MySQL 8各字段类型：无符号整数、tinyint(1)、bit、json、enum、set、二进制、decimal、日期时间及空间类型
+CREATE TABLE `type_sample` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `tiny` tinyint NOT NULL DEFAULT '0',
+  `tiny_u` tinyint unsigned NOT NULL DEFAULT '0',
+  `flag` tinyint(1) NOT NULL DEFAULT '0',
+  `small_u` smallint unsigned NOT NULL DEFAULT '0',
+  `medium` mediumint NOT NULL DEFAULT '0',
+  `int_u` int unsigned NOT NULL DEFAULT '0',
+  `bit1` bit(1) NOT NULL DEFAULT b'0',
+  `bit8` bit(8) NOT NULL DEFAULT b'0',
+  `price` decimal(10,2) NOT NULL DEFAULT '0.00',
+  `ratio` float NOT NULL DEFAULT '0',
+  `score` double NOT NULL DEFAULT '0',
+  `code` char(8) NOT NULL DEFAULT '',
+  `body` longtext NOT NULL,
+  `doc` json NOT NULL,
+  `state` enum('a','b') NOT NULL DEFAULT 'a',
+  `tags` set('x','y') NOT NULL DEFAULT '',
+  `hash` binary(16) NOT NULL,
+  `token` varbinary(64) NOT NULL,
+  `file` longblob NOT NULL,
+  `born` date NOT NULL,
+  `clock` time NOT NULL,
+  `yr` year NOT NULL,
+  `seen_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `geo` geometry NOT NULL,
+  `pos` point NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB COMMENT='字段类型';
@@ -0,0 +1,280 @@
+// Package sql2struct
+// @Title 字段类型转换
+// @Description mysql、postgresql、sqlite字段类型转go类型，处理可空字段及类型依赖的包
+// @Author shigx 2024-06-10 15:32:40
+package sql2struct
+
+import (
+	"github.com/pkg/errors"
+	"path"
+	"regexp"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// 可空字段处理方式
+const (
+	NullableNone    = "none"    // 不处理，使用普通类型
+	NullableSql     = "sql"     // database/sql 的 sql.NullXxx 类型
+	NullablePointer = "pointer" // 指针类型
+	NullableGorm    = "gorm"    // gorm.io/datatypes 的 datatypes.NullXxx 类型
+)
+
+// json字段类型
+const (
+	JsonRaw       = "raw"       // json.RawMessage
+	JsonDatatypes = "datatypes" // datatypes.JSON
+	JsonString    = "string"    // string
+)
+
+// decimal字段类型
+const (
+	DecimalFloat   = "float64" // float64
+	DecimalString  = "string"  // string
+	DecimalDecimal = "decimal" // github.com/shopspring/decimal 的 decimal.Decimal
+)
+
+// Config @Description struct生成配置
+// @Auth shigx
+// @Date 2024-06-10 15:32:40
+type Config struct {
+	Nullable     string        // 可空字段处理方式，见 Nullable* 常量
+	TinyintBool  bool          // tinyint(1) 是否转为 bool
+	JsonType     string        // json字段类型，见 Json* 常量
+	DecimalType  string        // decimal字段类型，见 Decimal* 常量
+	Types        []TypeMapping // 自定义类型映射，优先于内置类型转换
+	TagDialect   string        // gorm标签风格，见 Tag* 常量
+	Tags         []string      // 额外输出的标签：json、db、xorm、bun、form
+	JsonStyle    string        // json、form标签命名风格，见 JsonStyle* 常量
+	Initialisms  []string      // 自定义缩写词，例：SKU
+	Package      string        // 包名，为空时使用表名
+	TrimPrefix   []string      // 生成结构体名时去除的表前缀，例：t_
+	Singular     bool          // 结构体名是否转为单数
+	StructPrefix string        // 结构体名前缀
+	StructSuffix string        // 结构体名后缀
+	Template     string        // 自定义模版文件，为空时使用默认模版
+	Associations bool          // 是否按外键生成 BelongsTo、HasMany 关联字段
+	Driver       string        // 表结构来源的数据库驱动，决定内置类型转换，默认mysql
+}
+
+// TypeMapping @Description 自定义类型映射，匹配条件支持通配符，优先级：表名.字段名 > 字段名 > 完整字段类型 > 数据类型
+// @Auth shigx
+// @Date 2024-06-12 11:05:18
+type TypeMapping struct {
+	DataType    string `mapstructure:"data_type"`    // 数据类型，例：decimal
+	ColumnType  string `mapstructure:"column_type"`  // 完整字段类型，例：tinyint(1)、bigint unsigned
+	Column      string `mapstructure:"column"`       // 字段名，例：*_at
+	TableColumn string `mapstructure:"table_column"` // 表名.字段名，例：user.status
+	Type        string `mapstructure:"type"`         // go类型，例：decimal.Decimal
+	Import      string `mapstructure:"import"`       // 类型所在包导入路径，例：github.com/shopspring/decimal
+}
+
+// goType go类型及其依赖的包
+type goType struct {
+	Name    string       // 类型名，例：time.Time
+	Imports []importSpec // 依赖的包
+}
+
+// packageImports 类型中包名对应的导入路径
+var packageImports = map[string]string{
+	"time":      "time",
+	"sql":       "database/sql",
+	"json":      "encoding/json",
+	"datatypes": "gorm.io/datatypes",
+	"decimal":   "github.com/shopspring/decimal",
+	"pq":        "github.com/lib/pq",
+}
+
+// nilableTypes 本身可以表示NULL的类型
+var nilableTypes = map[string]bool{
+	"json.RawMessage": true,
+	"datatypes.JSON":  true,
+	"pq.BoolArray":    true,
+	"pq.Int32Array":   true,
+	"pq.Int64Array":   true,
+	"pq.Float32Array": true,
+	"pq.Float64Array": true,
+	"pq.ByteaArray":   true,
+	"pq.StringArray":  true,
+}
+
+var sqlNullTypes = map[string]string{
+	"string":          "sql.NullString",
+	"int64":           "sql.NullInt64",
+	"int32":           "sql.NullInt32",
+	"int16":           "sql.NullInt16",
+	"byte":            "sql.NullByte",
+	"float64":         "sql.NullFloat64",
+	"bool":            "sql.NullBool",
+	"time.Time":       "sql.NullTime",
+	"decimal.Decimal": "decimal.NullDecimal",
+}
+
+var gormNullTypes = map[string]string{
+	"string":          "datatypes.NullString",
+	"int64":           "datatypes.NullInt64",
+	"int32":           "datatypes.NullInt32",
+	"int16":           "datatypes.NullInt16",
+	"byte":            "datatypes.NullByte",
+	"float64":         "datatypes.NullFloat64",
+	"bool":            "datatypes.NullBool",
+	"time.Time":       "datatypes.NullTime",
+	"decimal.Decimal": "decimal.NullDecimal",
+}
+
+var qualifierRegexp = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// getColumnType
+//
+//	@Description: 根据字段类型（COLUMN_TYPE）返回对应的go类型，优先使用自定义类型映射，可空字段按配置转换
+//	@Auth shigx 2024-06-10 15:32:40
+//	@param tableName
+//	@param row
+//	@param config
+//	@return goType
+//	@return error
+func getColumnType(tableName string, row schema.TableColumn, config *Config) (goType, error) {
+	if mapping := matchTypeMapping(config.Types, tableName, row); mapping != nil {
+		var imports []importSpec
+		if mapping.Import != "" {
+			qualifier := ""
+			if match := qualifierRegexp.FindStringSubmatch(mapping.Type); match != nil {
+				qualifier = match[1]
+			}
+			imports = append(imports, newImportSpec(mapping.Import, qualifier))
+		}
+		t := newGoType(mapping.Type, imports...)
+		if row.IsNullable != "YES" {
+			return t, nil
+		}
+		return nullableType(t, config.Nullable), nil
+	}
+
+	var name string
+	switch config.Driver {
+	case schema.DriverPostgres:
+		name = getPostgresType(row.DataType, config)
+	case schema.DriverSqlite:
+		name = getSqliteType(row.DataType, config)
+	default:
+		name = getMysqlType(row, config)
+	}
+	if name == "" {
+		return goType{}, errors.Errorf("column %s: unsupported type %s", row.ColumnName, row.ColumnType)
+	}
+
+	t := newGoType(name)
+	if row.IsNullable != "YES" {
+		return t, nil
+	}
+
+	return nullableType(t, config.Nullable), nil
+}
+
+// getMysqlType 根据mysql字段类型返回go类型，unsigned整型为uint64，tinyint(1)、json、decimal按配置转换，不支持的类型返回空
+func getMysqlType(row schema.TableColumn, config *Config) string {
+	name := TextToType(row.DataType)
+	columnType := strings.ToLower(row.ColumnType)
+	switch row.DataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if strings.Contains(columnType, "unsigned") {
+			name = "uint64"
+		}
+		if config.TinyintBool && strings.HasPrefix(columnType, "tinyint(1)") {
+			name = "bool"
+		}
+	case "json":
+		switch config.JsonType {
+		case JsonDatatypes:
+			name = "datatypes.JSON"
+		case JsonString:
+			name = "string"
+		}
+	case "decimal", "numeric":
+		switch config.DecimalType {
+		case DecimalString:
+			name = "string"
+		case DecimalDecimal:
+			name = "decimal.Decimal"
+		}
+	}
+
+	return name
+}
+
+// matchTypeMapping
+//
+//	@Description: 按优先级查找字段匹配的自定义类型映射，同一优先级按配置顺序取第一个
+//	@Auth shigx 2024-06-12 11:05:18
+//	@param mappings
+//	@param tableName
+//	@param row
+//	@return *TypeMapping
+func matchTypeMapping(mappings []TypeMapping, tableName string, row schema.TableColumn) *TypeMapping {
+	getters := []func(m *TypeMapping) (pattern string, value string){
+		func(m *TypeMapping) (string, string) { return m.TableColumn, tableName + "." + row.ColumnName },
+		func(m *TypeMapping) (string, string) { return m.Column, row.ColumnName },
+		func(m *TypeMapping) (string, string) { return m.ColumnType, row.ColumnType },
+		func(m *TypeMapping) (string, string) { return m.DataType, row.DataType },
+	}
+	for _, getter := range getters {
+		for k := range mappings {
+			pattern, value := getter(&mappings[k])
+			if pattern == "" || mappings[k].Type == "" {
+				continue
+			}
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value)); ok {
+				return &mappings[k]
+			}
+		}
+	}
+
+	return nil
+}
+
+// newGoType 根据类型名中的包名推断依赖的包，已指定导入路径的包名不再推断
+func newGoType(name string, imports ...importSpec) goType {
+	t := goType{Name: name, Imports: imports}
+	known := make(map[string]bool)
+	for _, spec := range imports {
+		known[spec.name()] = true
+	}
+	for _, match := range qualifierRegexp.FindAllStringSubmatch(name, -1) {
+		if pkg, ok := packageImports[match[1]]; ok && !known[match[1]] {
+			t.Imports = append(t.Imports, newImportSpec(pkg, match[1]))
+			known[match[1]] = true
+		}
+	}
+
+	return t
+}
+
+// nullableType
+//
+//	@Description: 按可空处理方式转换类型，切片、映射、指针等本身可表示NULL的类型不做转换
+//	@Auth shigx 2024-06-10 15:32:40
+//	@param t
+//	@param strategy
+//	@return goType
+func nullableType(t goType, strategy string) goType {
+	if nilableTypes[t.Name] || strings.HasPrefix(t.Name, "[]") || strings.HasPrefix(t.Name, "map[") || strings.HasPrefix(t.Name, "*") {
+		return t
+	}
+
+	switch strategy {
+	case NullableSql:
+		if name, ok := sqlNullTypes[t.Name]; ok {
+			return newGoType(name)
+		}
+		return newGoType("sql.Null["+t.Name+"]", t.Imports...)
+	case NullableGorm:
+		if name, ok := gormNullTypes[t.Name]; ok {
+			return newGoType(name)
+		}
+		return newGoType("datatypes.Null["+t.Name+"]", t.Imports...)
+	case NullablePointer:
+		return goType{Name: "*" + t.Name, Imports: t.Imports}
+	}
+
+	return t
+}
@@ -0,0 +1,80 @@
+// Package sqlite
+// @Title sqlite初始化
+// @Description 使用纯go实现的sqlite驱动打开数据库文件，从 PRAGMA 及 sqlite_master 读取表结构
+// @Author shigx 2024-08-26 10:15:42
+package sqlite
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var _ Repo = (*dbRepo)(nil)
+
+type Repo interface {
+	GetDb() *gorm.DB
+	CloseDb() error
+}
+
+type dbRepo struct {
+	DbConn *gorm.DB
+}
+
+// New
+//
+//	@Description: 打开sqlite数据库，dsn为数据库文件路径，支持 file: 开头的uri及查询参数，文件不存在时报错而不是创建空库
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param dsn 例：./data/app.db、file:app.db?mode=ro
+//	@return Repo
+//	@return error
+func New(dsn string) (Repo, error) {
+	file := FileName(dsn)
+	if file != ":memory:" {
+		if _, err := os.Stat(file); err != nil {
+			return nil, errors.Wrap(err, "sqlite数据库文件不存在")
+		}
+	}
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("[db connection failed] Database file: %s", file))
+	}
+
+	return &dbRepo{
+		DbConn: db,
+	}, nil
+}
+
+// FileName 返回dsn中的数据库文件路径，去除 file: 前缀及查询参数
+func FileName(dsn string) string {
+	file := strings.TrimPrefix(dsn, "file:")
+	if k := strings.Index(file, "?"); k >= 0 {
+		file = file[:k]
+	}
+
+	return file
+}
+
+// DbName 返回数据库名，为不含扩展名的文件名，例：./data/app.db => app
+func DbName(dsn string) string {
+	base := filepath.Base(FileName(dsn))
+
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func (d *dbRepo) GetDb() *gorm.DB {
+	return d.DbConn
+}
+
+func (d *dbRepo) CloseDb() error {
+	sqlDB, err := d.DbConn.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"tool-cli/internal/schema"
+)
+
+// testDdl 测试库表结构：单字段整型主键、字段及表注释、唯一约束、表达式索引、命名及未命名外键、组合主键
+var testDdl = []string{
+	"CREATE TABLE user ( -- 用户\n" +
+		"  id INTEGER PRIMARY KEY, -- 主键\n" +
+		"  name VARCHAR(32) NOT NULL DEFAULT '', -- 名称\n" +
+		"  email TEXT UNIQUE,\n" +
+		"  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  score REAL DEFAULT 1.5\n" +
+		")",
+	"CREATE INDEX idx_user_name ON user (name, lower(email) DESC)",
+	"CREATE TABLE post (\n" +
+		"  id INTEGER PRIMARY KEY,\n" +
+		"  user_id INTEGER NOT NULL,\n" +
+		"  editor_id INTEGER,\n" +
+		"  CONSTRAINT fk_post_author FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE,\n" +
+		"  FOREIGN KEY (editor_id) REFERENCES user\n" +
+		")",
+	"CREATE TABLE tag_post (tag TEXT, post_id INTEGER REFERENCES post (id), PRIMARY KEY (tag, post_id))",
+}
+
+// openTestDb 在临时目录创建测试库
+func openTestDb(t *testing.T) Repo {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := New(path)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = repo.CloseDb()
+	})
+	for _, ddl := range testDdl {
+		if err := repo.GetDb().Exec(ddl).Error; err != nil {
+			t.Fatalf("exec %q: %v", ddl, err)
+		}
+	}
+
+	return repo
+}
+
+var (
+	fkPostAuthor = schema.ForeignKey{
+		Name: "fk_post_author", Table: "post", Columns: []string{"user_id"},
+		ReferencedTable: "user", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "CASCADE",
+	}
+	fkPostEditor = schema.ForeignKey{
+		Name: "fk_post_editor_id", Table: "post", Columns: []string{"editor_id"},
+		ReferencedTable: "user", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "NO ACTION",
+	}
+	fkTagPost = schema.ForeignKey{
+		Name: "fk_tag_post_post_id", Table: "tag_post", Columns: []string{"post_id"},
+		ReferencedTable: "post", ReferencedColumns: []string{"id"}, OnUpdate: "NO ACTION", OnDelete: "NO ACTION",
+	}
+)
+
+func TestGetTableNames(t *testing.T) {
+	names, err := GetTableNames(openTestDb(t).GetDb())
+	if err != nil {
+		t.Fatalf("GetTableNames() error = %v", err)
+	}
+	if want := []string{"post", "tag_post", "user"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("GetTableNames() = %v, want %v", names, want)
+	}
+}
+
+func TestGetTableColumn(t *testing.T) {
+	db := openTestDb(t).GetDb()
+
+	columns, primary, err := GetTableColumn(db, "user")
+	if err != nil {
+		t.Fatalf("GetTableColumn() error = %v", err)
+	}
+	want := []schema.TableColumn{
+		{OrdinalPosition: 1, ColumnName: "id", ColumnType: "INTEGER", DataType: "integer", IsNullable: "NO", AutoIncrement: true},
+		{OrdinalPosition: 2, ColumnName: "name", ColumnType: "VARCHAR(32)", DataType: "varchar", IsNullable: "NO", ColumnDefault: sql.NullString{Valid: true}},
+		{OrdinalPosition: 3, ColumnName: "email", ColumnType: "TEXT", DataType: "text", IsNullable: "YES"},
+		{OrdinalPosition: 4, ColumnName: "created_at", ColumnType: "DATETIME", DataType: "datetime", IsNullable: "YES", ColumnDefault: sql.NullString{String: "CURRENT_TIMESTAMP", Valid: true}, DefaultExpr: true},
+		{OrdinalPosition: 5, ColumnName: "score", ColumnType: "REAL", DataType: "real", IsNullable: "YES", ColumnDefault: sql.NullString{String: "1.5", Valid: true}},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("GetTableColumn(user) =\n%+v\nwant\n%+v", columns, want)
+	}
+	if primary == nil || !reflect.DeepEqual(primary.ColumnNames(), []string{"id"}) {
+		t.Errorf("GetTableColumn(user) primary = %+v, want (id)", primary)
+	}
+
+	// 组合主键不自增
+	columns, primary, err = GetTableColumn(db, "tag_post")
+	if err != nil {
+		t.Fatalf("GetTableColumn() error = %v", err)
+	}
+	for _, column := range columns {
+		if column.AutoIncrement || column.IsNullable != "NO" {
+			t.Errorf("GetTableColumn(tag_post) %s auto_increment = %v nullable = %s, want false NO", column.ColumnName, column.AutoIncrement, column.IsNullable)
+		}
+	}
+	if primary == nil || !reflect.DeepEqual(primary.ColumnNames(), []string{"tag", "post_id"}) {
+		t.Errorf("GetTableColumn(tag_post) primary = %+v, want (tag, post_id)", primary)
+	}
+}
+
+func TestGetTableIndex(t *testing.T) {
+	indexes, err := GetTableIndex(openTestDb(t).GetDb(), "user")
+	if err != nil {
+		t.Fatalf("GetTableIndex() error = %v", err)
+	}
+	want := []schema.TableIndex{
+		{Name: "idx_user_name", Type: "BTREE", Columns: []schema.IndexColumn{{Name: "name"}, {Expression: "lower(email)"}}},
+		{Name: "sqlite_autoindex_user_1", Unique: true, Type: "BTREE", Columns: []schema.IndexColumn{{Name: "email"}}},
+	}
+	if !reflect.DeepEqual(indexes, want) {
+		t.Errorf("GetTableIndex(user) =\n%+v\nwant\n%+v", indexes, want)
+	}
+}
+
+func TestGetReferencingForeignKeys(t *testing.T) {
+	db := openTestDb(t).GetDb()
+
+	foreignKeys, err := GetTableForeignKey(db, "post", testDdl[2])
+	if err != nil {
+		t.Fatalf("GetTableForeignKey() error = %v", err)
+	}
+	if want := []schema.ForeignKey{fkPostAuthor, fkPostEditor}; !reflect.DeepEqual(foreignKeys, want) {
+		t.Errorf("GetTableForeignKey(post) =\n%+v\nwant\n%+v", foreignKeys, want)
+	}
+
+	referencedBy, err := GetReferencingForeignKeys(db)
+	if err != nil {
+		t.Fatalf("GetReferencingForeignKeys() error = %v", err)
+	}
+	want := map[string][]schema.ForeignKey{
+		"user": {fkPostAuthor, fkPostEditor},
+		"post": {fkTagPost},
+	}
+	if !reflect.DeepEqual(referencedBy, want) {
+		t.Errorf("GetReferencingForeignKeys() =\n%+v\nwant\n%+v", referencedBy, want)
+	}
+}
+
+func TestGetTable(t *testing.T) {
+	db := openTestDb(t).GetDb()
+	referencedBy, err := GetReferencingForeignKeys(db)
+	if err != nil {
+		t.Fatalf("GetReferencingForeignKeys() error = %v", err)
+	}
+
+	table, err := GetTable(db, "user", referencedBy["user"])
+	if err != nil {
+		t.Fatalf("GetTable() error = %v", err)
+	}
+	if table.Comment != "用户" {
+		t.Errorf("GetTable(user) comment = %q, want 用户", table.Comment)
+	}
+	comments := make(map[string]string)
+	keys := make(map[string]string)
+	for _, column := range table.Columns {
+		comments[column.ColumnName] = column.ColumnComment.String
+		keys[column.ColumnName] = column.ColumnKey.String
+	}
+	if want := map[string]string{"id": "主键", "name": "名称", "email": "", "created_at": "", "score": ""}; !reflect.DeepEqual(comments, want) {
+		t.Errorf("GetTable(user) column comments = %v, want %v", comments, want)
+	}
+	if want := map[string]string{"id": "PRI", "name": "MUL", "email": "UNI", "created_at": "", "score": ""}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("GetTable(user) column keys = %v, want %v", keys, want)
+	}
+	if len(table.Indexes) != 3 || !table.Indexes[0].IsPrimary() {
+		t.Errorf("GetTable(user) indexes = %+v, want PRIMARY first and 2 secondary indexes", table.Indexes)
+	}
+	if want := []schema.ForeignKey{fkPostAuthor, fkPostEditor}; !reflect.DeepEqual(table.ReferencedBy, want) {
+		t.Errorf("GetTable(user) referenced by =\n%+v\nwant\n%+v", table.ReferencedBy, want)
+	}
+
+	if _, err := GetTable(db, "missing", nil); err == nil {
+		t.Error("GetTable(missing) error = nil, want not found error")
+	}
+}
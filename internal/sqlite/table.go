@@ -0,0 +1,495 @@
+// Package sqlite
+// @Description: 从 PRAGMA table_info、index_list、foreign_key_list 读取字段、索引、外键，备注及外键约束名解析自 sqlite_master 中的建表语句
+// @Auth shigx 2024-08-26 10:15:42
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"regexp"
+	"sort"
+	"strings"
+	"tool-cli/internal/schema"
+)
+
+// GetTableNames
+//
+//	@Description: 查询库中全部数据表名，不包含 sqlite_ 开头的内部表，按表名排序
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@return []string
+//	@return error
+func GetTableNames(db *gorm.DB) ([]string, error) {
+	ret := make([]string, 0)
+	err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\\_%' ESCAPE '\\' ORDER BY name ASC").
+		Scan(&ret).
+		Error
+
+	return ret, err
+}
+
+// GetTable
+//
+//	@Description: 查询表字段、索引、外键信息，sqlite不支持备注，表、字段备注取自建表语句中的 -- 注释
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@param tableName
+//	@param referencedBy 其他表引用本表的外键，读取多个表时由 GetReferencingForeignKeys 一次查询
+//	@return *schema.Table
+//	@return error
+func GetTable(db *gorm.DB, tableName string, referencedBy []schema.ForeignKey) (*schema.Table, error) {
+	ddl := make([]string, 0)
+	err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).
+		Scan(&ddl).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ddl) == 0 {
+		return nil, errors.Errorf("table %s not found", tableName)
+	}
+	tableComment, columnComments := parseComments(ddl[0])
+
+	columns, primary, err := GetTableColumn(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for k := range columns {
+		if comment, ok := columnComments[strings.ToLower(columns[k].ColumnName)]; ok {
+			columns[k].ColumnComment = sql.NullString{String: comment, Valid: true}
+		}
+	}
+	indexes, err := GetTableIndex(db, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if primary != nil {
+		indexes = append([]schema.TableIndex{*primary}, indexes...)
+	}
+	schema.SetColumnKeys(columns, indexes)
+	foreignKeys, err := GetTableForeignKey(db, tableName, ddl[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.Table{
+		Name:         tableName,
+		Comment:      tableComment,
+		Columns:      columns,
+		Indexes:      indexes,
+		ForeignKeys:  foreignKeys,
+		ReferencedBy: referencedBy,
+	}, nil
+}
+
+// columnRow pragma_table_info 查询结果
+type columnRow struct {
+	Cid       int64
+	Name      string
+	Type      string
+	NotNull   bool
+	DfltValue sql.NullString
+	Pk        int64
+}
+
+// GetTableColumn
+//
+//	@Description: 返回表字段信息及主键，INTEGER PRIMARY KEY 字段为rowid别名，视为自增
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@param tableName
+//	@return []schema.TableColumn
+//	@return *schema.TableIndex 主键，无主键时为nil
+//	@return error
+func GetTableColumn(db *gorm.DB, tableName string) ([]schema.TableColumn, *schema.TableIndex, error) {
+	rows := make([]columnRow, 0)
+	err := db.Raw(`SELECT cid, name, type, "notnull" AS not_null, dflt_value, pk FROM pragma_table_info(?) ORDER BY cid ASC`, tableName).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := make([]schema.TableColumn, 0, len(rows))
+	keys := make([]columnRow, 0)
+	for _, row := range rows {
+		column := schema.TableColumn{
+			OrdinalPosition: row.Cid + 1,
+			ColumnName:      row.Name,
+			ColumnType:      row.Type,
+			DataType:        dataType(row.Type),
+			IsNullable:      "YES",
+		}
+		if row.NotNull || row.Pk > 0 {
+			column.IsNullable = "NO"
+		}
+		column.ColumnDefault, column.DefaultExpr = parseDefault(row.DfltValue)
+		if row.Pk > 0 {
+			keys = append(keys, row)
+		}
+		ret = append(ret, column)
+	}
+	if len(keys) == 0 {
+		return ret, nil, nil
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Pk < keys[j].Pk
+	})
+	primary := &schema.TableIndex{Name: schema.PrimaryKey, Unique: true, Type: "BTREE"}
+	for _, key := range keys {
+		primary.Columns = append(primary.Columns, schema.IndexColumn{Name: key.Name})
+	}
+	if len(keys) == 1 && strings.EqualFold(keys[0].Type, "INTEGER") {
+		for k := range ret {
+			if ret[k].ColumnName == keys[0].Name {
+				ret[k].AutoIncrement = true
+			}
+		}
+	}
+
+	return ret, primary, nil
+}
+
+// dataType 返回不含长度、精度的小写类型名，例：VARCHAR(32) => varchar，未声明类型时为空
+func dataType(columnType string) string {
+	if k := strings.Index(columnType, "("); k >= 0 {
+		columnType = columnType[:k]
+	}
+
+	return strings.ToLower(strings.TrimSpace(columnType))
+}
+
+var (
+	// literalRegexp 字符串常量
+	literalRegexp = regexp.MustCompile(`^'((?:[^']|'')*)'$`)
+	// numberRegexp 数值常量
+	numberRegexp = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+)
+
+// parseDefault 返回默认值及是否为表达式，字符串常量去除引号，非数值常量为表达式，例：CURRENT_TIMESTAMP、(datetime('now'))
+func parseDefault(value sql.NullString) (sql.NullString, bool) {
+	switch {
+	case !value.Valid, strings.EqualFold(value.String, "NULL"):
+		return sql.NullString{}, false
+	case literalRegexp.MatchString(value.String):
+		literal := literalRegexp.FindStringSubmatch(value.String)[1]
+		return sql.NullString{String: strings.ReplaceAll(literal, "''", "'"), Valid: true}, false
+	case numberRegexp.MatchString(value.String):
+		return value, false
+	}
+
+	return value, true
+}
+
+// indexRow pragma_index_list 查询结果
+type indexRow struct {
+	Name   string
+	Unique bool
+	Origin string
+}
+
+// indexColumnRow pragma_index_info 查询结果，表达式的字段名为空
+type indexColumnRow struct {
+	Seqno int64
+	Name  sql.NullString
+}
+
+// GetTableIndex
+//
+//	@Description: 返回表索引信息（不含主键），按索引名排序，唯一约束自动创建的索引名为 sqlite_autoindex_表名_序号，表达式索引的表达式解析自 CREATE INDEX 语句
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@param tableName
+//	@return []schema.TableIndex
+//	@return error
+func GetTableIndex(db *gorm.DB, tableName string) ([]schema.TableIndex, error) {
+	rows := make([]indexRow, 0)
+	err := db.Raw(`SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name ASC`, tableName).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]schema.TableIndex, 0, len(rows))
+	for _, row := range rows {
+		// 主键取自 table_info
+		if row.Origin == "pk" {
+			continue
+		}
+		columns := make([]indexColumnRow, 0)
+		err := db.Raw(`SELECT seqno, name FROM pragma_index_info(?) ORDER BY seqno ASC`, row.Name).
+			Scan(&columns).
+			Error
+		if err != nil {
+			return nil, err
+		}
+		index := schema.TableIndex{Name: row.Name, Unique: row.Unique, Type: "BTREE"}
+		var expressions []string
+		for _, column := range columns {
+			if column.Name.Valid {
+				index.Columns = append(index.Columns, schema.IndexColumn{Name: column.Name.String})
+				continue
+			}
+			if expressions == nil {
+				if expressions, err = indexExpressions(db, row.Name); err != nil {
+					return nil, err
+				}
+			}
+			if int(column.Seqno) < len(expressions) {
+				index.Columns = append(index.Columns, schema.IndexColumn{Expression: expressions[column.Seqno]})
+			}
+		}
+		if len(index.Columns) > 0 {
+			ret = append(ret, index)
+		}
+	}
+
+	return ret, nil
+}
+
+// indexExpressions 返回 CREATE INDEX 语句中按顺序的索引项，例：CREATE INDEX idx ON t (lower(name), age) => lower(name)、age
+func indexExpressions(db *gorm.DB, indexName string) ([]string, error) {
+	ddl := make([]string, 0)
+	err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?", indexName).
+		Scan(&ddl).
+		Error
+	if err != nil || len(ddl) == 0 {
+		return []string{}, err
+	}
+
+	// ON 表名后第一个括号内为索引项，按最外层逗号拆分
+	ret := make([]string, 0)
+	var quote byte
+	depth, start := 0, -1
+	for k := 0; k < len(ddl[0]); k++ {
+		c := ddl[0][k]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+			if depth == 1 && start < 0 {
+				start = k + 1
+			}
+		case c == ')' || (c == ',' && depth == 1):
+			if depth == 1 && start >= 0 {
+				ret = append(ret, strings.TrimSpace(ddl[0][start:k]))
+				start = k + 1
+			}
+			if c == ')' {
+				depth--
+				if depth == 0 && start >= 0 {
+					return trimIndexOrder(ret), nil
+				}
+			}
+		}
+	}
+
+	return trimIndexOrder(ret), nil
+}
+
+// trimIndexOrder 去除索引项的排序及排序规则，例：lower(name) DESC => lower(name)
+func trimIndexOrder(items []string) []string {
+	for k, item := range items {
+		upper := strings.ToUpper(item)
+		for _, suffix := range []string{" ASC", " DESC"} {
+			if strings.HasSuffix(upper, suffix) {
+				item, upper = strings.TrimSpace(item[:len(item)-len(suffix)]), strings.TrimSpace(upper[:len(upper)-len(suffix)])
+			}
+		}
+		if i := strings.LastIndex(upper, " COLLATE "); i >= 0 && !strings.Contains(upper[i:], ")") {
+			item = strings.TrimSpace(item[:i])
+		}
+		items[k] = item
+	}
+
+	return items
+}
+
+// foreignKeyRow pragma_foreign_key_list 查询结果
+type foreignKeyRow struct {
+	Id       int64
+	Table    string
+	From     string
+	To       sql.NullString
+	OnUpdate string
+	OnDelete string
+}
+
+// GetTableForeignKey
+//
+//	@Description: 返回表的外键信息，约束名取自建表语句中的 CONSTRAINT，未命名的外键按 fk_表名_字段名 命名，按约束名排序
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@param tableName
+//	@param ddl 建表语句
+//	@return []schema.ForeignKey
+//	@return error
+func GetTableForeignKey(db *gorm.DB, tableName string, ddl string) ([]schema.ForeignKey, error) {
+	rows := make([]foreignKeyRow, 0)
+	err := db.Raw(`SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id ASC, seq ASC`, tableName).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	names := constraintNames(ddl)
+	ret := make([]schema.ForeignKey, 0)
+	ids := make(map[int64]int)
+	for _, row := range rows {
+		k, ok := ids[row.Id]
+		if !ok {
+			k = len(ret)
+			ids[row.Id] = k
+			ret = append(ret, schema.ForeignKey{
+				Table:           tableName,
+				ReferencedTable: row.Table,
+				OnUpdate:        row.OnUpdate,
+				OnDelete:        row.OnDelete,
+			})
+		}
+		ret[k].Columns = append(ret[k].Columns, row.From)
+		ret[k].ReferencedColumns = append(ret[k].ReferencedColumns, row.To.String)
+	}
+	for k := range ret {
+		fk := &ret[k]
+		fk.Name = names[strings.ToLower(strings.Join(fk.Columns, ","))]
+		if fk.Name == "" {
+			fk.Name = fmt.Sprintf("fk_%s_%s", tableName, strings.Join(fk.Columns, "_"))
+		}
+		// 未指定关联字段时引用关联表的主键
+		if fk.ReferencedColumns[0] == "" {
+			_, primary, err := GetTableColumn(db, fk.ReferencedTable)
+			if err != nil {
+				return nil, err
+			}
+			if primary != nil {
+				fk.ReferencedColumns = primary.ColumnNames()
+			}
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret, nil
+}
+
+// GetReferencingForeignKeys
+//
+//	@Description: 一次读取全部表的外键，返回被引用的表名（小写） => 引用该表的外键，按表名、约束名排序
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param db
+//	@return map[string][]schema.ForeignKey
+//	@return error
+func GetReferencingForeignKeys(db *gorm.DB) (map[string][]schema.ForeignKey, error) {
+	type tableRow struct {
+		Name string
+		Sql  string
+	}
+	tables := make([]tableRow, 0)
+	err := db.Raw("SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\\_%' ESCAPE '\\' ORDER BY name ASC").
+		Scan(&tables).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string][]schema.ForeignKey)
+	for _, table := range tables {
+		foreignKeys, err := GetTableForeignKey(db, table.Name, table.Sql)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range foreignKeys {
+			name := strings.ToLower(fk.ReferencedTable)
+			ret[name] = append(ret[name], fk)
+		}
+	}
+
+	return ret, nil
+}
+
+// constraintRegexp 命名的外键约束，例：CONSTRAINT fk_order_user FOREIGN KEY (user_id)
+var constraintRegexp = regexp.MustCompile("(?i)CONSTRAINT\\s+([\"`\\[]?[\\w]+[\"`\\]]?)\\s+FOREIGN\\s+KEY\\s*\\(([^)]*)\\)")
+
+// constraintNames 返回建表语句中命名的外键约束，小写逗号分隔的字段名 => 约束名
+func constraintNames(ddl string) map[string]string {
+	ret := make(map[string]string)
+	for _, match := range constraintRegexp.FindAllStringSubmatch(ddl, -1) {
+		columns := strings.Split(match[2], ",")
+		for k := range columns {
+			columns[k] = unquoteName(columns[k])
+		}
+		ret[strings.ToLower(strings.Join(columns, ","))] = unquoteName(match[1])
+	}
+
+	return ret
+}
+
+// parseComments
+//
+//	@Description: 解析建表语句中的行尾 -- 注释，CREATE TABLE 所在行的注释为表备注，字段定义所在行的注释为字段备注
+//	@Auth shigx 2024-08-26 10:15:42
+//	@param ddl
+//	@return string 表备注
+//	@return map[string]string 小写字段名 => 字段备注
+func parseComments(ddl string) (string, map[string]string) {
+	tableComment, columns := "", make(map[string]string)
+	for _, line := range strings.Split(ddl, "\n") {
+		k := commentIndex(line)
+		if k < 0 {
+			continue
+		}
+		comment, code := strings.TrimSpace(line[k+2:]), strings.TrimLeft(strings.TrimSpace(line[:k]), ", ")
+		fields := strings.Fields(code)
+		switch {
+		case len(fields) == 0:
+		case strings.EqualFold(fields[0], "CREATE"):
+			tableComment = comment
+		case !constraintKeywords[strings.ToUpper(fields[0])]:
+			columns[strings.ToLower(unquoteName(fields[0]))] = comment
+		}
+	}
+
+	return tableComment, columns
+}
+
+// constraintKeywords 表级约束定义的开头关键字
+var constraintKeywords = map[string]bool{
+	"PRIMARY": true, "UNIQUE": true, "CHECK": true, "FOREIGN": true, "CONSTRAINT": true, ")": true, ");": true,
+}
+
+// commentIndex 返回行中引号外 -- 注释的位置，没有注释返回-1
+func commentIndex(line string) int {
+	var quote byte
+	for k := 0; k < len(line); k++ {
+		c := line[k]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && k+1 < len(line) && line[k+1] == '-':
+			return k
+		}
+	}
+
+	return -1
+}
+
+// unquoteName 去除标识符两侧的空白及引号
+func unquoteName(name string) string {
+	return strings.Trim(strings.TrimSpace(name), "\"`[]")
+}
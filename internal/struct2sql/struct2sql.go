@@ -0,0 +1,565 @@
+// Package struct2sql
+// @Description: 解析带 gorm 标签的 go 结构体，生成表结构
+// @Auth shigx 2024-08-05 16:12:48
+package struct2sql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/jinzhu/inflection"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/tmpl"
+)
+
+// Config @Description 生成配置
+// @Auth shigx
+// @Date 2024-08-05 16:12:48
+type Config struct {
+	Structs  []string // 只处理指定的结构体，为空时处理全部模型
+	Singular bool     // 没有 TableName 方法时表名不转为复数
+}
+
+// model 解析到的结构体
+type model struct {
+	name    string
+	doc     string
+	fields  *ast.FieldList
+	isModel bool // 是否有 gorm 标签、TableName 方法或嵌入 gorm.Model
+}
+
+// parserState 结构体解析结果
+type parserState struct {
+	models     []*model
+	byName     map[string]*model
+	tableNames map[string]string // 结构体名 => TableName 方法返回的表名
+}
+
+// Parse
+//
+//	@Description: 解析go文件或目录下全部go文件（不含测试文件）中的结构体，按文件及定义顺序返回表结构
+//	@Auth shigx 2024-08-05 16:12:48
+//	@param path
+//	@param config
+//	@return []schema.Table
+//	@return error
+func Parse(path string, config *Config) ([]schema.Table, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	files, err := goFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &parserState{byName: make(map[string]*model), tableNames: make(map[string]string)}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		state.collect(f)
+	}
+
+	tables := make([]schema.Table, 0)
+	for _, m := range state.models {
+		if len(config.Structs) > 0 {
+			if !contains(config.Structs, m.name) {
+				continue
+			}
+		} else if !m.isModel && state.tableNames[m.name] == "" {
+			continue
+		}
+		table, err := state.table(m, config)
+		if err != nil {
+			return nil, errors.WithMessage(err, m.name)
+		}
+		tables = append(tables, *table)
+	}
+	for _, name := range config.Structs {
+		if state.byName[name] == nil {
+			return nil, errors.Errorf("结构体 %s 不存在", name)
+		}
+	}
+
+	return tables, nil
+}
+
+// goFiles 返回文件或目录下的go文件，按文件名排序
+func goFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(files))
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_test.go") {
+			ret = append(ret, file)
+		}
+	}
+	sort.Strings(ret)
+
+	return ret, nil
+}
+
+// collect 收集文件中的结构体及 TableName 方法
+func (s *parserState) collect(f *ast.File) {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				st, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				doc := typeSpec.Doc
+				if doc == nil && len(d.Specs) == 1 {
+					doc = d.Doc
+				}
+				m := &model{name: typeSpec.Name.Name, doc: commentText(doc), fields: st.Fields, isModel: hasGormField(st.Fields)}
+				s.models = append(s.models, m)
+				s.byName[m.name] = m
+			}
+		case *ast.FuncDecl:
+			if name, table, ok := tableNameMethod(d); ok {
+				s.tableNames[name] = table
+			}
+		}
+	}
+}
+
+// tableNameMethod 解析 func (X) TableName() string { return "table" }
+func tableNameMethod(d *ast.FuncDecl) (string, string, bool) {
+	if d.Name.Name != "TableName" || d.Recv == nil || len(d.Recv.List) != 1 || d.Body == nil || len(d.Body.List) != 1 {
+		return "", "", false
+	}
+	recv := d.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	ret, ok := d.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	table, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+
+	return ident.Name, table, true
+}
+
+// hasGormField 是否有 gorm 标签字段或嵌入 gorm.Model
+func hasGormField(fields *ast.FieldList) bool {
+	for _, field := range fields.List {
+		if _, ok := gormTag(field); ok {
+			return true
+		}
+		if len(field.Names) == 0 && typeName(field.Type) == "gorm.Model" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tableBuilder 单表构建状态
+type tableBuilder struct {
+	table   *schema.Table
+	primary []string
+	manual  map[string]bool // 显式设置 autoIncrement:false 的字段
+	indexes map[string]*indexBuilder
+	order   []string // 索引名，按出现顺序
+}
+
+// indexBuilder 索引构建状态
+type indexBuilder struct {
+	index   schema.TableIndex
+	columns []indexColumn
+}
+
+// indexColumn 带优先级的索引字段
+type indexColumn struct {
+	column   schema.IndexColumn
+	priority int
+}
+
+// table 生成结构体对应的表结构
+func (s *parserState) table(m *model, config *Config) (*schema.Table, error) {
+	name := s.tableNames[m.name]
+	if name == "" {
+		name = tmpl.Snake(m.name)
+		if !config.Singular {
+			name = inflection.Plural(name)
+		}
+	}
+	b := &tableBuilder{
+		table:   &schema.Table{Name: name, Comment: tableComment(m)},
+		manual:  make(map[string]bool),
+		indexes: make(map[string]*indexBuilder),
+	}
+	s.addFields(b, m.fields, "", map[string]bool{m.name: true})
+	if len(b.table.Columns) == 0 {
+		return nil, errors.New("没有可生成的字段")
+	}
+	b.finish()
+
+	return b.table, nil
+}
+
+// addFields 添加结构体字段，嵌入的结构体展开为字段
+func (s *parserState) addFields(b *tableBuilder, fields *ast.FieldList, prefix string, visiting map[string]bool) {
+	for _, field := range fields.List {
+		tag, _ := gormTag(field)
+		settings := parseSettings(tag)
+		if tag == "-" || strings.HasPrefix(tag, "-:") {
+			continue
+		}
+
+		// 嵌入结构体
+		if len(field.Names) == 0 || hasSetting(settings, "EMBEDDED") {
+			if typeName(field.Type) == "gorm.Model" {
+				s.addGormModel(b, prefix)
+				continue
+			}
+			embedded := s.byName[strings.TrimPrefix(typeName(field.Type), "*")]
+			if embedded == nil || visiting[embedded.name] {
+				continue
+			}
+			visiting[embedded.name] = true
+			s.addFields(b, embedded.fields, prefix+settings["EMBEDDEDPREFIX"], visiting)
+			delete(visiting, embedded.name)
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			if column, ok := s.column(ident.Name, field, settings, prefix); ok {
+				b.addColumn(column, settings)
+			}
+		}
+	}
+}
+
+// addGormModel 添加 gorm.Model 的字段
+func (s *parserState) addGormModel(b *tableBuilder, prefix string) {
+	b.addColumn(newColumn(prefix+"id", "bigint unsigned", ""), map[string]string{"PRIMARYKEY": "PRIMARYKEY"})
+	b.addColumn(newColumn(prefix+"created_at", "datetime(3)", ""), map[string]string{})
+	b.addColumn(newColumn(prefix+"updated_at", "datetime(3)", ""), map[string]string{})
+	b.addColumn(newColumn(prefix+"deleted_at", "datetime(3)", ""), map[string]string{"INDEX": ""})
+}
+
+// column 解析字段，不支持的类型（如关联字段）返回false
+func (s *parserState) column(name string, field *ast.Field, settings map[string]string, prefix string) (schema.TableColumn, bool) {
+	columnName := settings["COLUMN"]
+	if columnName == "" {
+		columnName = prefix + tmpl.Snake(name)
+	}
+
+	columnType := settings["TYPE"]
+	if columnType == "" {
+		var ok bool
+		if columnType, ok = s.goColumnType(field.Type, settings); !ok {
+			return schema.TableColumn{}, false
+		}
+	}
+
+	// gorm v1 风格的备注带单引号，例：comment:'名称'
+	comment := settings["COMMENT"]
+	if len(comment) >= 2 && strings.HasPrefix(comment, "'") && strings.HasSuffix(comment, "'") {
+		comment = strings.ReplaceAll(comment[1:len(comment)-1], "''", "'")
+	}
+	if comment == "" {
+		comment = fieldComment(field)
+	}
+	column := newColumn(columnName, columnType, comment)
+	if hasSetting(settings, "NOT NULL") || hasSetting(settings, "NOT_NULL") {
+		column.IsNullable = "NO"
+	}
+	if value, ok := settings["DEFAULT"]; ok && value != "" && !strings.EqualFold(value, "null") {
+		if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")
+			column.DefaultExpr = true
+		} else if len(value) >= 2 && strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		} else {
+			// default:CURRENT_TIMESTAMP 与建表语句、MySQL 8 一致视为表达式
+			value, column.DefaultExpr = schema.CurrentTimestamp(value)
+		}
+		column.ColumnDefault = sql.NullString{String: value, Valid: true}
+	}
+
+	return column, true
+}
+
+// addColumn 添加字段及字段上的主键、索引
+func (b *tableBuilder) addColumn(column schema.TableColumn, settings map[string]string) {
+	column.OrdinalPosition = int64(len(b.table.Columns) + 1)
+	primary := hasSetting(settings, "PRIMARYKEY") || hasSetting(settings, "PRIMARY_KEY")
+	if primary {
+		b.primary = append(b.primary, column.ColumnName)
+	}
+	autoIncrement := hasSetting(settings, "AUTOINCREMENT") || hasSetting(settings, "AUTO_INCREMENT")
+	if value := settings["AUTOINCREMENT"]; strings.EqualFold(value, "false") {
+		autoIncrement = false
+		b.manual[column.ColumnName] = true
+	}
+	column.AutoIncrement = autoIncrement
+	b.table.Columns = append(b.table.Columns, column)
+
+	for _, key := range []string{"INDEX", "UNIQUEINDEX", "UNIQUE_INDEX"} {
+		value, ok := settings[key]
+		if !ok {
+			continue
+		}
+		// 未指定索引名时值与键相同
+		if strings.ToUpper(value) == key {
+			value = ""
+		}
+		b.addIndex(column.ColumnName, value, key != "INDEX", "idx_")
+	}
+	if hasSetting(settings, "UNIQUE") {
+		b.addIndex(column.ColumnName, "", true, "uni_")
+	}
+}
+
+// addIndex 解析索引设置，例：idx_name,priority:2,unique,class:FULLTEXT,type:btree,length:10,comment:备注
+func (b *tableBuilder) addIndex(column string, value string, unique bool, prefix string) {
+	var (
+		name     string
+		priority = 10
+		length   int64
+		class    string
+		comment  string
+		kind     string
+	)
+	for k, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		key, val, hasValue := strings.Cut(part, ":")
+		if k == 0 && !hasValue {
+			name = part
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "PRIORITY":
+			if n, err := strconv.Atoi(val); err == nil {
+				priority = n
+			}
+		case "LENGTH":
+			length, _ = strconv.ParseInt(val, 10, 64)
+		case "CLASS":
+			class = strings.ToUpper(val)
+		case "TYPE":
+			kind = strings.ToUpper(val)
+		case "COMMENT":
+			comment = val
+		case "UNIQUE":
+			unique = true
+		}
+	}
+	if name == "" {
+		name = prefix + b.table.Name + "_" + column
+	}
+
+	index, ok := b.indexes[name]
+	if !ok {
+		index = &indexBuilder{index: schema.TableIndex{Name: name, Type: "BTREE"}}
+		b.indexes[name] = index
+		b.order = append(b.order, name)
+	}
+	index.index.Unique = index.index.Unique || unique || class == "UNIQUE"
+	switch {
+	case class == "FULLTEXT", class == "SPATIAL":
+		index.index.Type = class
+	case kind != "":
+		index.index.Type = kind
+	}
+	if comment != "" {
+		index.index.Comment = comment
+	}
+	index.columns = append(index.columns, indexColumn{column: schema.IndexColumn{Name: column, Length: length}, priority: priority})
+}
+
+// finish 补充默认主键，生成索引及字段键信息
+func (b *tableBuilder) finish() {
+	// 没有指定主键时，id 字段为主键，整型主键自增
+	if len(b.primary) == 0 {
+		for _, column := range b.table.Columns {
+			if column.ColumnName == "id" {
+				b.primary = append(b.primary, column.ColumnName)
+			}
+		}
+	}
+	if len(b.primary) > 0 {
+		index := schema.TableIndex{Name: schema.PrimaryKey, Unique: true, Type: "BTREE"}
+		for _, name := range b.primary {
+			index.Columns = append(index.Columns, schema.IndexColumn{Name: name})
+		}
+		b.table.Indexes = append(b.table.Indexes, index)
+	}
+	for k := range b.table.Columns {
+		column := &b.table.Columns[k]
+		if !contains(b.primary, column.ColumnName) {
+			continue
+		}
+		column.IsNullable = "NO"
+		if len(b.primary) == 1 && integerTypes[column.DataType] && !column.DefaultExpr && !b.manual[column.ColumnName] {
+			column.AutoIncrement = true
+		}
+	}
+
+	for _, name := range b.order {
+		index := b.indexes[name]
+		sort.SliceStable(index.columns, func(i, j int) bool {
+			return index.columns[i].priority < index.columns[j].priority
+		})
+		for _, column := range index.columns {
+			index.index.Columns = append(index.index.Columns, column.column)
+		}
+		b.table.Indexes = append(b.table.Indexes, index.index)
+	}
+}
+
+// newColumn 创建字段，数据类型取完整类型的第一个单词
+func newColumn(name string, columnType string, comment string) schema.TableColumn {
+	dataType := strings.ToLower(columnType)
+	if k := strings.IndexAny(dataType, "( "); k >= 0 {
+		dataType = dataType[:k]
+	}
+
+	return schema.TableColumn{
+		ColumnName:    name,
+		ColumnType:    columnType,
+		DataType:      dataType,
+		IsNullable:    "YES",
+		ColumnComment: sql.NullString{String: comment, Valid: comment != ""},
+	}
+}
+
+// parseSettings 解析gorm标签，键转为大写，例：column:id;primaryKey => {COLUMN: id, PRIMARYKEY: PRIMARYKEY}
+func parseSettings(tag string) map[string]string {
+	settings := make(map[string]string)
+	parts := strings.Split(strings.ReplaceAll(tag, `\;`, "\x00"), ";")
+	for _, part := range parts {
+		part = strings.TrimSpace(strings.ReplaceAll(part, "\x00", ";"))
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, ":")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if !ok {
+			value = key
+		}
+		settings[key] = strings.TrimSpace(value)
+	}
+
+	return settings
+}
+
+// hasSetting 标签中是否存在指定设置，值为 false 时视为不存在
+func hasSetting(settings map[string]string, key string) bool {
+	value, ok := settings[key]
+	return ok && !strings.EqualFold(value, "false")
+}
+
+// gormTag 返回字段的gorm标签
+func gormTag(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+
+	return reflect.StructTag(tag).Lookup("gorm")
+}
+
+// typeName 返回类型表达式的名称，例：time.Time、*User、[]byte
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + typeName(t.X)
+	case *ast.SelectorExpr:
+		return typeName(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + typeName(t.Elt)
+	case *ast.IndexExpr:
+		return typeName(t.X) + "[" + typeName(t.Index) + "]"
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
+
+// tableComment 返回结构体注释，去除开头的结构体名
+func tableComment(m *model) string {
+	comment := tmpl.Oneline(m.doc)
+	if rest, ok := strings.CutPrefix(comment, m.name); ok && (rest == "" || rest[0] == ' ') {
+		comment = strings.TrimSpace(rest)
+	}
+
+	return comment
+}
+
+// fieldComment 返回字段注释，优先使用行尾注释
+func fieldComment(field *ast.Field) string {
+	if field.Comment != nil {
+		return tmpl.Oneline(commentText(field.Comment))
+	}
+
+	return tmpl.Oneline(commentText(field.Doc))
+}
+
+// commentText 返回注释文本
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(group.Text())
+}
+
+// contains 判断列表中是否包含指定值
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,274 @@
+package struct2sql
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"tool-cli/internal/diff"
+	"tool-cli/internal/mysql"
+	"tool-cli/internal/schema"
+	"tool-cli/internal/sql2struct"
+)
+
+// parseSource 将go源码写入临时目录并解析
+func parseSource(t *testing.T, src string, config *Config) []schema.Table {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "model.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := Parse(path, config)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	return tables
+}
+
+// definitions 返回表的字段及索引定义，例：name varchar(32) NOT NULL、PRIMARY BTREE (id)
+func definitions(table schema.Table) ([]string, []string) {
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column.ColumnName+" "+diff.ColumnDefinition(column))
+	}
+	indexes := make([]string, 0, len(table.Indexes))
+	for _, index := range table.Indexes {
+		indexes = append(indexes, index.Name+" "+diff.IndexDefinition(index))
+	}
+
+	return columns, indexes
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		table   string
+		columns []string
+		indexes []string
+	}{
+		{
+			name: "gorm v1 tags",
+			src: `package model
+
+type User struct {
+	ID   uint   ` + "`" + `gorm:"primary_key;AUTO_INCREMENT"` + "`" + `
+	Name string ` + "`" + `gorm:"column:user_name;type:varchar(32);NOT NULL;default:'';unique_index:uk_name;comment:'it''s'"` + "`" + `
+	Age  int    ` + "`" + `gorm:"not_null;index"` + "`" + ` // 年龄
+}
+`,
+			table: "users",
+			columns: []string{
+				"id bigint unsigned NOT NULL auto_increment",
+				"user_name varchar(32) NOT NULL DEFAULT '' COMMENT 'it''s'",
+				"age bigint NOT NULL COMMENT '年龄'",
+			},
+			indexes: []string{
+				"PRIMARY BTREE (id)",
+				"uk_name UNIQUE BTREE (user_name)",
+				"idx_users_age BTREE (age)",
+			},
+		},
+		{
+			name: "gorm v2 tags",
+			src: `package model
+
+type User struct {
+	UserID int64  ` + "`" + `gorm:"primaryKey;autoIncrement:false"` + "`" + `
+	Email  string ` + "`" + `gorm:"size:64;not null;uniqueIndex:uk_email;comment:邮箱"` + "`" + `
+	Score  float64 ` + "`" + `gorm:"default:0"` + "`" + `
+}
+
+func (User) TableName() string { return "t_user" }
+`,
+			table: "t_user",
+			columns: []string{
+				"user_id bigint NOT NULL",
+				"email varchar(64) NOT NULL COMMENT '邮箱'",
+				"score double DEFAULT '0'",
+			},
+			indexes: []string{
+				"PRIMARY BTREE (user_id)",
+				"uk_email UNIQUE BTREE (email)",
+			},
+		},
+		{
+			name: "embedded and embedded prefix",
+			src: `package model
+
+import "gorm.io/gorm"
+
+type Author struct {
+	Name  string
+	Email string
+}
+
+// Blog 博客
+type Blog struct {
+	gorm.Model
+	Author  Author ` + "`" + `gorm:"embedded;embeddedPrefix:author_"` + "`" + `
+	Upvotes int32
+}
+`,
+			table: "blogs",
+			columns: []string{
+				"id bigint unsigned NOT NULL auto_increment",
+				"created_at datetime(3)",
+				"updated_at datetime(3)",
+				"deleted_at datetime(3)",
+				"author_name longtext",
+				"author_email longtext",
+				"upvotes int",
+			},
+			indexes: []string{
+				"PRIMARY BTREE (id)",
+				"idx_blogs_deleted_at BTREE (deleted_at)",
+			},
+		},
+		{
+			name: "index priority and options",
+			src: `package model
+
+type Order struct {
+	ID     int64
+	Status int8   ` + "`" + `gorm:"index:idx_user_status,priority:2"` + "`" + `
+	UserID int64  ` + "`" + `gorm:"index:idx_user_status,priority:1"` + "`" + `
+	No     string ` + "`" + `gorm:"index:idx_no,length:10,type:hash"` + "`" + `
+	Remark string ` + "`" + `gorm:"index:,class:FULLTEXT,comment:备注"` + "`" + `
+	Code   string ` + "`" + `gorm:"unique"` + "`" + `
+}
+`,
+			table: "orders",
+			columns: []string{
+				"id bigint NOT NULL auto_increment",
+				"status tinyint",
+				"user_id bigint",
+				"no varchar(191)",
+				"remark varchar(191)",
+				"code varchar(191)",
+			},
+			indexes: []string{
+				"PRIMARY BTREE (id)",
+				"idx_user_status BTREE (user_id, status)",
+				"idx_no HASH (no(10))",
+				"idx_orders_remark FULLTEXT (remark)",
+				"uni_orders_code UNIQUE BTREE (code)",
+			},
+		},
+		{
+			name: "default expressions",
+			src: `package model
+
+import "time"
+
+type Event struct {
+	ID        string    ` + "`" + `gorm:"primaryKey;size:36;default:(uuid())"` + "`" + `
+	Title     string    ` + "`" + `gorm:"default:'it''s'"` + "`" + `
+	CreatedAt time.Time ` + "`" + `gorm:"default:CURRENT_TIMESTAMP"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `gorm:"precision:6;default:now(6)"` + "`" + `
+	Payload   []byte
+	Meta      map[string]string ` + "`" + `gorm:"serializer:json"` + "`" + `
+	Ignored   string ` + "`" + `gorm:"-"` + "`" + `
+}
+`,
+			table: "events",
+			columns: []string{
+				"id varchar(36) NOT NULL DEFAULT uuid()",
+				"title varchar(191) DEFAULT 'it''s'",
+				"created_at datetime(3) DEFAULT CURRENT_TIMESTAMP",
+				"updated_at datetime(6) DEFAULT CURRENT_TIMESTAMP(6)",
+				"payload longblob",
+				"meta json",
+			},
+			indexes: []string{
+				"PRIMARY BTREE (id)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables := parseSource(t, tt.src, nil)
+			if len(tables) != 1 {
+				t.Fatalf("Parse() tables = %d, want 1", len(tables))
+			}
+			if tables[0].Name != tt.table {
+				t.Errorf("Parse() table = %s, want %s", tables[0].Name, tt.table)
+			}
+			columns, indexes := definitions(tables[0])
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("Parse() columns = %q, want %q", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(indexes, tt.indexes) {
+				t.Errorf("Parse() indexes = %q, want %q", indexes, tt.indexes)
+			}
+		})
+	}
+}
+
+func TestParseStructs(t *testing.T) {
+	src := `package model
+
+type Plain struct {
+	Name string
+}
+
+type Tagged struct {
+	ID int ` + "`" + `gorm:"primaryKey"` + "`" + `
+}
+`
+	tables := parseSource(t, src, nil)
+	if len(tables) != 1 || tables[0].Name != "taggeds" {
+		t.Errorf("Parse() without structs = %v, want only taggeds", tables)
+	}
+
+	tables = parseSource(t, src, &Config{Structs: []string{"Plain"}, Singular: true})
+	if len(tables) != 1 || tables[0].Name != "plain" {
+		t.Errorf("Parse(Plain) = %v, want plain", tables)
+	}
+
+	path := filepath.Join(t.TempDir(), "model.go")
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(path, &Config{Structs: []string{"Missing"}}); err == nil {
+		t.Error("Parse(Missing) error = nil, want not exist error")
+	}
+}
+
+// TestRoundTrip 建表语句经 sql2struct 生成 gorm v2 结构体，再经 struct2sql 解析，表结构应与原表一致；
+// gorm v1 标签不含字段类型，无法还原
+func TestRoundTrip(t *testing.T) {
+	ddl := "CREATE TABLE `t_order` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `order_no` varchar(32) NOT NULL DEFAULT '' COMMENT '订单号',\n" +
+		"  `user_id` bigint NOT NULL COMMENT '用户ID',\n" +
+		"  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态',\n" +
+		"  `amount` decimal(10,2) NOT NULL DEFAULT '0.00',\n" +
+		"  `remark` text,\n" +
+		"  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_order_no` (`order_no`),\n" +
+		"  KEY `idx_user_status` (`user_id`,`status`),\n" +
+		"  KEY `idx_remark` (`remark`(20))\n" +
+		") COMMENT='订单';"
+	tables, err := mysql.ParseDdl(ddl)
+	if err != nil {
+		t.Fatalf("ParseDdl() error = %v", err)
+	}
+
+	code, err := sql2struct.GetModelTemplate(&tables[0], &sql2struct.Config{TagDialect: sql2struct.TagGorm2, Package: "model"})
+	if err != nil {
+		t.Fatalf("GetModelTemplate() error = %v", err)
+	}
+	parsed := parseSource(t, string(code), nil)
+	if len(parsed) != 1 {
+		t.Fatalf("Parse() tables = %d, want 1\n%s", len(parsed), code)
+	}
+
+	source := &schema.Schema{Version: schema.SchemaVersion, Tables: tables}
+	target := &schema.Schema{Version: schema.SchemaVersion, Tables: parsed}
+	if result := diff.Compare(source, target); !result.Empty() {
+		t.Errorf("Compare() = %s, want no changes\n%s", result.Text(), code)
+	}
+}
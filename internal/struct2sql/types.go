@@ -0,0 +1,144 @@
+// Package struct2sql
+// @Description: go类型转mysql字段类型，与gorm mysql驱动的默认类型保持一致
+// @Auth shigx 2024-08-05 16:12:48
+package struct2sql
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// goTypeToMysqlType go类型对应的mysql类型，string、[]byte、decimal 按 size、precision、scale 计算
+var goTypeToMysqlType = map[string]string{
+	"bool":            "tinyint(1)",
+	"int":             "bigint",
+	"int8":            "tinyint",
+	"int16":           "smallint",
+	"int32":           "int",
+	"int64":           "bigint",
+	"uint":            "bigint unsigned",
+	"uint8":           "tinyint unsigned",
+	"byte":            "tinyint unsigned",
+	"uint16":          "smallint unsigned",
+	"uint32":          "int unsigned",
+	"uint64":          "bigint unsigned",
+	"float32":         "float",
+	"float64":         "double",
+	"time.Time":       "datetime",
+	"json.RawMessage": "json",
+	"datatypes.JSON":  "json",
+	"datatypes.Date":  "date",
+	"datatypes.Time":  "time",
+	"gorm.DeletedAt":  "datetime",
+	"sql.NullBool":    "tinyint(1)",
+	"sql.NullByte":    "tinyint unsigned",
+	"sql.NullInt16":   "smallint",
+	"sql.NullInt32":   "int",
+	"sql.NullInt64":   "bigint",
+	"sql.NullFloat64": "double",
+	"sql.NullTime":    "datetime",
+}
+
+// nullWrappers 可空包装类型，例：sql.NullString、datatypes.Null[T]
+var nullWrappers = map[string]string{
+	"sql.NullString":        "string",
+	"datatypes.NullString":  "string",
+	"datatypes.NullInt64":   "int64",
+	"datatypes.NullInt32":   "int32",
+	"datatypes.NullInt16":   "int16",
+	"datatypes.NullByte":    "uint8",
+	"datatypes.NullBool":    "bool",
+	"datatypes.NullFloat64": "float64",
+	"datatypes.NullTime":    "time.Time",
+	"decimal.NullDecimal":   "decimal.Decimal",
+}
+
+// integerTypes 整型数据类型，单字段整型主键默认自增
+var integerTypes = map[string]bool{
+	"tinyint": true, "smallint": true, "mediumint": true, "int": true, "bigint": true,
+}
+
+// goColumnType 按go类型返回mysql字段类型，关联字段等不支持的类型返回false
+func (s *parserState) goColumnType(expr ast.Expr, settings map[string]string) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return s.goColumnType(t.X, settings)
+	case *ast.IndexExpr:
+		// sql.Null[T]、datatypes.Null[T]
+		if name := typeName(t.X); name == "sql.Null" || name == "datatypes.Null" {
+			return s.goColumnType(t.Index, settings)
+		}
+		return "", false
+	}
+
+	name := typeName(expr)
+	if wrapped, ok := nullWrappers[name]; ok {
+		name = wrapped
+	}
+	switch serializer := strings.ToLower(settings["SERIALIZER"]); serializer {
+	case "json":
+		return "json", true
+	case "gob":
+		return "longblob", true
+	case "unixtime":
+		return "bigint", true
+	}
+
+	switch name {
+	case "string":
+		return stringType(settings), true
+	case "[]byte", "[]uint8":
+		if size := intSetting(settings, "SIZE"); size > 0 {
+			return fmt.Sprintf("varbinary(%d)", size), true
+		}
+		return "longblob", true
+	case "decimal.Decimal":
+		precision, scale := intSetting(settings, "PRECISION"), intSetting(settings, "SCALE")
+		if precision == 0 {
+			precision = 10
+		}
+		return fmt.Sprintf("decimal(%d,%d)", precision, scale), true
+	}
+	columnType, ok := goTypeToMysqlType[name]
+	if ok && columnType == "datetime" {
+		// 与gorm默认精度一致，精确到毫秒
+		precision := 3
+		if value, has := settings["PRECISION"]; has {
+			precision, _ = strconv.Atoi(value)
+		}
+		if precision > 0 {
+			columnType = fmt.Sprintf("datetime(%d)", precision)
+		}
+	}
+
+	return columnType, ok
+}
+
+// stringType 字符串类型，未指定 size 时主键、索引、默认值字段为 varchar(191)，其他为 longtext
+func stringType(settings map[string]string) string {
+	size := intSetting(settings, "SIZE")
+	if size == 0 {
+		for _, key := range []string{"PRIMARYKEY", "PRIMARY_KEY", "INDEX", "UNIQUEINDEX", "UNIQUE_INDEX", "UNIQUE", "DEFAULT"} {
+			if _, ok := settings[key]; ok {
+				return "varchar(191)"
+			}
+		}
+		return "longtext"
+	}
+	switch {
+	case size >= 16777216:
+		return "longtext"
+	case size > 65535:
+		return "mediumtext"
+	}
+
+	return fmt.Sprintf("varchar(%d)", size)
+}
+
+// intSetting 返回整数设置，不存在或格式错误时为0
+func intSetting(settings map[string]string, key string) int {
+	n, _ := strconv.Atoi(settings[key])
+	return n
+}
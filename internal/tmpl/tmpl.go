@@ -0,0 +1,134 @@
+// Package tmpl
+// @Title 模板加载
+// @Description 自定义模板文件加载及模板公共函数
+// @Author shigx 2024-06-24 10:30:52
+package tmpl
+
+import (
+	"github.com/jinzhu/inflection"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// FuncMap
+//
+//	@Description: 模板公共函数
+//	  camel    首字母小写驼峰，user_id => userId
+//	  pascal   首字母大写驼峰，user_id => UserId
+//	  snake    小写下划线，UserID => user_id
+//	  plural   复数，user => users
+//	  singular 单数，users => user
+//	  quote    go字符串字面量，a"b => "a\"b"
+//	  upper、lower、title、trim、join、replace、contains、hasPrefix、hasSuffix 同strings包
+//	  oneline  去除换行，用于注释
+//	@Auth shigx 2024-06-24 10:30:52
+//	@return template.FuncMap
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"camel":     Camel,
+		"pascal":    Pascal,
+		"snake":     Snake,
+		"plural":    inflection.Plural,
+		"singular":  inflection.Singular,
+		"quote":     strconv.Quote,
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"title":     Pascal,
+		"trim":      strings.TrimSpace,
+		"join":      func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"oneline":   Oneline,
+	}
+}
+
+// Parse
+//
+//	@Description: 解析模板，file不为空时使用自定义模板文件，否则使用默认模板
+//	@Auth shigx 2024-06-24 10:30:52
+//	@param name 模板名
+//	@param file 自定义模板文件
+//	@param text 默认模板内容
+//	@return *template.Template
+//	@return error
+func Parse(name string, file string, text string) (*template.Template, error) {
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "read template err")
+		}
+		text = string(content)
+	}
+
+	t, err := template.New(name).Funcs(FuncMap()).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "template init err")
+	}
+
+	return t, nil
+}
+
+// OutputExt 返回模板文件名中 .tpl、.tmpl 前的扩展名，用作生成文件的扩展名，例：sql2md.html.tpl => html，没有时返回空
+func OutputExt(file string) string {
+	base := filepath.Base(file)
+	if ext := filepath.Ext(base); ext == ".tpl" || ext == ".tmpl" {
+		base = strings.TrimSuffix(base, ext)
+	}
+
+	return strings.TrimPrefix(filepath.Ext(base), ".")
+}
+
+// Camel 转首字母小写驼峰，例：user_id => userId
+func Camel(s string) string {
+	words := strings.FieldsFunc(Snake(s), func(r rune) bool { return r == '_' })
+	for k := 1; k < len(words); k++ {
+		runes := []rune(words[k])
+		words[k] = string(unicode.ToUpper(runes[0])) + string(runes[1:])
+	}
+
+	return strings.Join(words, "")
+}
+
+// Pascal 转首字母大写驼峰，例：user_id => UserId
+func Pascal(s string) string {
+	camel := Camel(s)
+	if camel == "" {
+		return ""
+	}
+	runes := []rune(camel)
+
+	return string(unicode.ToUpper(runes[0])) + string(runes[1:])
+}
+
+// Snake 驼峰字符串转小写下划线，例：UserID => user_id
+func Snake(s string) string {
+	var buf strings.Builder
+	runes := []rune(s)
+	for k, r := range runes {
+		if unicode.IsUpper(r) && k > 0 && runes[k-1] != '_' {
+			prev := runes[k-1]
+			nextLower := k+1 < len(runes) && unicode.IsLower(runes[k+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (nextLower && unicode.IsUpper(prev)) {
+				buf.WriteByte('_')
+			}
+		}
+		if r == '-' || r == ' ' {
+			r = '_'
+		}
+		buf.WriteRune(r)
+	}
+
+	return strings.ToLower(buf.String())
+}
+
+// Oneline 去除换行，例：用于单行注释
+func Oneline(s string) string {
+	return strings.Join(strings.Fields(strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)), " ")
+}
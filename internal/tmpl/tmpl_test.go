@@ -0,0 +1,108 @@
+package tmpl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		in     string
+		camel  string
+		pascal string
+		snake  string
+	}{
+		{"user_id", "userId", "UserId", "user_id"},
+		{"UserID", "userId", "UserId", "user_id"},
+		{"HTTPServer", "httpServer", "HttpServer", "http_server"},
+		{"order-item name", "orderItemName", "OrderItemName", "order_item_name"},
+		{"user_id2", "userId2", "UserId2", "user_id2"},
+		{"用户_名称", "用户名称", "用户名称", "用户_名称"},
+		{"", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := Camel(tt.in); got != tt.camel {
+			t.Errorf("Camel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := Pascal(tt.in); got != tt.pascal {
+			t.Errorf("Pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		if got := Snake(tt.in); got != tt.snake {
+			t.Errorf("Snake(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	tests := []struct {
+		text string
+		data interface{}
+		want string
+	}{
+		{`{{plural .}}`, "category", "categories"},
+		{`{{plural .}}`, "user_info", "user_infos"},
+		{`{{singular .}}`, "orders", "order"},
+		{`{{quote .}}`, `a"b` + "\n", `"a\"b\n"`},
+		{`{{. | snake | upper}}`, "OrderItem", "ORDER_ITEM"},
+		{`{{oneline .}}`, "第一行\r\n  第二行\n", "第一行 第二行"},
+		{`{{join "," .}}`, []string{"a", "b"}, "a,b"},
+		{`{{replace "_" "-" .}}`, "a_b_c", "a-b-c"},
+		{`{{if hasSuffix "_at" .}}time{{end}}`, "created_at", "time"},
+	}
+	for _, tt := range tests {
+		tpl, err := Parse("test", "", tt.text)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.text, err)
+		}
+		var buf strings.Builder
+		if err := tpl.Execute(&buf, tt.data); err != nil {
+			t.Fatalf("Execute(%q) error = %v", tt.text, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s with %v = %q, want %q", tt.text, tt.data, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "doc.md.tpl")
+	if err := os.WriteFile(file, []byte("# {{pascal .}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tpl, err := Parse("doc", file, "default")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, "user_order"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "# UserOrder" {
+		t.Errorf("Parse(file) output = %q, want %q", buf.String(), "# UserOrder")
+	}
+
+	if _, err := Parse("doc", filepath.Join(t.TempDir(), "missing.tpl"), "default"); err == nil {
+		t.Error("Parse(missing file) error = nil, want read error")
+	}
+	if _, err := Parse("doc", "", "{{.Name"); err == nil {
+		t.Error("Parse(invalid) error = nil, want parse error")
+	}
+}
+
+func TestOutputExt(t *testing.T) {
+	tests := map[string]string{
+		"sql2md.tpl":            "",
+		"tpl/sql2md.html.tpl":   "html",
+		"api.adoc.tmpl":         "adoc",
+		"doc.md":                "md",
+		"":                      "",
+		"dir.v2/sql2struct.tpl": "",
+	}
+	for file, want := range tests {
+		if got := OutputExt(file); got != want {
+			t.Errorf("OutputExt(%q) = %q, want %q", file, got, want)
+		}
+	}
+}